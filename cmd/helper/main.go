@@ -40,6 +40,8 @@ func main() {
 	sherpa.Execute(func() error {
 		return sherpa.Helpers(map[string]sherpa.ExecD{
 			"linker": helper.FileLinker{Bindings: b, Logger: bard.NewLogger(os.Stdout)},
+			"debug":  helper.DebugConfigurer{Logger: bard.NewLogger(os.Stdout)},
+			"stack":  helper.StackRuntimeConfigurer{Logger: bard.NewLogger(os.Stdout)},
 		})
 	})
 }
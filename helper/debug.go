@@ -0,0 +1,123 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+const (
+	// debugProcessEnv is set for the `debug` process, which runs `server debug`
+	debugProcessEnv = "BPI_LIBERTY_DEBUG_PROCESS"
+
+	// defaultDebugPort is the default of BPL_DEBUG_PORT in the JVM buildpacks
+	defaultDebugPort = "8000"
+
+	debugTimeoutsConfig = "debug-timeouts.xml"
+	debugTimeout        = "1h"
+)
+
+// jdwpAgent matches the JDWP agent that the JVM buildpacks add to JAVA_TOOL_OPTIONS
+var jdwpAgent = regexp.MustCompile(`-agentlib:jdwp=\S*`)
+
+// DebugConfigurer configures Liberty's debug settings from the `BPL_DEBUG_*` variables of the JVM buildpacks, so that
+// `server debug` listens on the same port and the server does not time out while stepping through code.
+type DebugConfigurer struct {
+	Logger bard.Logger
+}
+
+func (d DebugConfigurer) Execute() (map[string]string, error) {
+	userPath, err := sherpa.GetEnvRequired("WLP_USER_DIR")
+	if err != nil {
+		return nil, err
+	}
+	serverNames, err := getServerNames()
+	if err != nil {
+		return nil, err
+	}
+	var timeoutsPaths []string
+	for _, serverName := range serverNames {
+		timeoutsPaths = append(timeoutsPaths, filepath.Join(userPath, "servers", serverName, "configDropins", "overrides", debugTimeoutsConfig))
+	}
+
+	if !sherpa.ResolveBool("BPL_DEBUG_ENABLED") {
+		for _, timeoutsPath := range timeoutsPaths {
+			if err := os.Remove(timeoutsPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("unable to remove %s\n%w", timeoutsPath, err)
+			}
+		}
+		return nil, nil
+	}
+
+	suspend := "n"
+	if sherpa.ResolveBool("BPL_DEBUG_SUSPEND") {
+		suspend = "y"
+	}
+	env := map[string]string{
+		"WLP_DEBUG_SUSPEND": suspend,
+		"WLP_DEBUG_REMOTE":  "y",
+	}
+
+	// An explicit BPL_DEBUG_PORT wins, otherwise the address set for the debug process from BP_LIBERTY_DEBUG_PORT is kept
+	port, ok := os.LookupEnv("BPL_DEBUG_PORT")
+	if ok && port != "" {
+		env["WLP_DEBUG_ADDRESS"] = port
+	} else if address := os.Getenv("WLP_DEBUG_ADDRESS"); address != "" {
+		port = address
+	} else {
+		port = defaultDebugPort
+		env["WLP_DEBUG_ADDRESS"] = port
+	}
+	d.Logger.Infof("Liberty debugging enabled on port %s", port)
+
+	// `server debug` adds its own agent and the JVM fails to start if the agent is loaded twice
+	if sherpa.ResolveBool(debugProcessEnv) {
+		if opts, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok && jdwpAgent.MatchString(opts) {
+			env["JAVA_TOOL_OPTIONS"] = strings.Join(strings.Fields(jdwpAgent.ReplaceAllString(opts, "")), " ")
+		}
+	}
+
+	for _, timeoutsPath := range timeoutsPaths {
+		if err := os.MkdirAll(filepath.Dir(timeoutsPath), 0755); err != nil {
+			return nil, fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(timeoutsPath), err)
+		}
+		if err := os.WriteFile(timeoutsPath, []byte(debugTimeoutsXML()), 0644); err != nil {
+			return nil, fmt.Errorf("unable to write %s\n%w", timeoutsPath, err)
+		}
+	}
+
+	return env, nil
+}
+
+// debugTimeoutsXML raises the timeouts that are likely to expire while the server is suspended in a debugger
+func debugTimeoutsXML() string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<server>
+    <applicationManager startTimeout="%[1]s" stopTimeout="%[1]s"/>
+    <transaction totalTranLifetimeTimeout="0"/>
+</server>
+`, debugTimeout)
+}
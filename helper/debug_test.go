@@ -0,0 +1,160 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/helper"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDebug(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		configurer   helper.DebugConfigurer
+		userDir      string
+		timeoutsPath string
+	)
+
+	it.Before(func() {
+		var err error
+
+		userDir, err = os.MkdirTemp("", "execd-helper-debug")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(userDir, "servers", "defaultServer"), 0755)).To(Succeed())
+		timeoutsPath = filepath.Join(userDir, "servers", "defaultServer", "configDropins", "overrides", "debug-timeouts.xml")
+
+		Expect(os.Setenv("WLP_USER_DIR", userDir)).To(Succeed())
+		Expect(os.Setenv("BPI_LIBERTY_SERVER_NAME", "defaultServer")).To(Succeed())
+
+		configurer = helper.DebugConfigurer{Logger: bard.NewLogger(io.Discard)}
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("WLP_USER_DIR")).To(Succeed())
+		Expect(os.Unsetenv("BPI_LIBERTY_SERVER_NAME")).To(Succeed())
+		Expect(os.RemoveAll(userDir)).To(Succeed())
+	})
+
+	it("does nothing when debugging is not enabled", func() {
+		env, err := configurer.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env).To(BeEmpty())
+		Expect(timeoutsPath).NotTo(BeAnExistingFile())
+	})
+
+	it("removes the timeouts when debugging is disabled", func() {
+		Expect(os.MkdirAll(filepath.Dir(timeoutsPath), 0755)).To(Succeed())
+		Expect(os.WriteFile(timeoutsPath, []byte("<server/>"), 0644)).To(Succeed())
+
+		_, err := configurer.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timeoutsPath).NotTo(BeAnExistingFile())
+	})
+
+	context("when debugging is enabled", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_DEBUG_ENABLED", "true")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BPL_DEBUG_ENABLED")).To(Succeed())
+			Expect(os.Unsetenv("BPL_DEBUG_PORT")).To(Succeed())
+			Expect(os.Unsetenv("BPL_DEBUG_SUSPEND")).To(Succeed())
+			Expect(os.Unsetenv("BPI_LIBERTY_DEBUG_PROCESS")).To(Succeed())
+			Expect(os.Unsetenv("JAVA_TOOL_OPTIONS")).To(Succeed())
+			Expect(os.Unsetenv("BPI_LIBERTY_SERVER_NAMES")).To(Succeed())
+			Expect(os.Unsetenv("WLP_DEBUG_ADDRESS")).To(Succeed())
+		})
+
+		it("configures the default debug settings and raises the timeouts", func() {
+			env, err := configurer.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(Equal(map[string]string{
+				"WLP_DEBUG_ADDRESS": "8000",
+				"WLP_DEBUG_SUSPEND": "n",
+				"WLP_DEBUG_REMOTE":  "y",
+			}))
+
+			Expect(timeoutsPath).To(BeARegularFile())
+			contents, err := os.ReadFile(timeoutsPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring(`<applicationManager startTimeout="1h" stopTimeout="1h"/>`))
+		})
+
+		it("raises the timeouts of every server", func() {
+			Expect(os.Setenv("BPI_LIBERTY_SERVER_NAMES", "defaultServer otherServer")).To(Succeed())
+
+			_, err := configurer.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeoutsPath).To(BeARegularFile())
+			Expect(filepath.Join(userDir, "servers", "otherServer", "configDropins", "overrides", "debug-timeouts.xml")).To(BeARegularFile())
+		})
+
+		it("uses the debug port and suspend settings", func() {
+			Expect(os.Setenv("BPL_DEBUG_PORT", "5005")).To(Succeed())
+			Expect(os.Setenv("BPL_DEBUG_SUSPEND", "true")).To(Succeed())
+
+			env, err := configurer.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(HaveKeyWithValue("WLP_DEBUG_ADDRESS", "5005"))
+			Expect(env).To(HaveKeyWithValue("WLP_DEBUG_SUSPEND", "y"))
+		})
+
+		it("keeps the debug port set for the debug process", func() {
+			Expect(os.Setenv("WLP_DEBUG_ADDRESS", "8787")).To(Succeed())
+
+			env, err := configurer.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).NotTo(HaveKey("WLP_DEBUG_ADDRESS"))
+		})
+
+		it("uses an explicit debug port over the one set for the debug process", func() {
+			Expect(os.Setenv("WLP_DEBUG_ADDRESS", "8787")).To(Succeed())
+			Expect(os.Setenv("BPL_DEBUG_PORT", "5005")).To(Succeed())
+
+			env, err := configurer.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(HaveKeyWithValue("WLP_DEBUG_ADDRESS", "5005"))
+		})
+
+		it("keeps the JVM debug agent for other processes", func() {
+			Expect(os.Setenv("JAVA_TOOL_OPTIONS", "-Xmx1g -agentlib:jdwp=transport=dt_socket,server=y,address=*:8000,suspend=n")).To(Succeed())
+
+			env, err := configurer.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).NotTo(HaveKey("JAVA_TOOL_OPTIONS"))
+		})
+
+		it("removes the JVM debug agent for the debug process", func() {
+			Expect(os.Setenv("BPI_LIBERTY_DEBUG_PROCESS", "true")).To(Succeed())
+			Expect(os.Setenv("JAVA_TOOL_OPTIONS", "-Xmx1g -agentlib:jdwp=transport=dt_socket,server=y,address=*:8000,suspend=n -Xss1m")).To(Succeed())
+
+			env, err := configurer.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(HaveKeyWithValue("JAVA_TOOL_OPTIONS", "-Xmx1g -Xss1m"))
+		})
+	})
+}
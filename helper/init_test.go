@@ -26,5 +26,7 @@ import (
 func TestUnit(t *testing.T) {
 	suite := spec.New("liberty-helper", spec.Report(report.Terminal{}))
 	suite("Link", testLink)
+	suite("Debug", testDebug)
+	suite("Stack", testStack)
 	suite.Run(t)
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/paketo-buildpacks/libpak/sherpa"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type FileLinker struct {
@@ -94,3 +95,16 @@ func getServerPath() (string, error) {
 
 	return filepath.Join(usrPath, "servers", serverName), nil
 }
+
+// getServerNames returns the names of the servers built by the buildpack. The first is the default server.
+func getServerNames() ([]string, error) {
+	if names := strings.Fields(os.Getenv("BPI_LIBERTY_SERVER_NAMES")); len(names) > 0 {
+		return names, nil
+	}
+
+	serverName, err := sherpa.GetEnvRequired("BPI_LIBERTY_SERVER_NAME")
+	if err != nil {
+		return nil, err
+	}
+	return []string{serverName}, nil
+}
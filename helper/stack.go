@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// StackRuntimeConfigurer configures the Liberty runtime provided in the stack run image to use the user directory
+// built by the buildpack, and checks that the runtime contains the features that the server requires.
+type StackRuntimeConfigurer struct {
+	Logger bard.Logger
+}
+
+func (s StackRuntimeConfigurer) Execute() (map[string]string, error) {
+	if !sherpa.ResolveBool("BPI_LIBERTY_STACK_RUNTIME") {
+		return nil, nil
+	}
+
+	runtimePath, err := sherpa.GetEnvRequired("BPI_LIBERTY_RUNTIME_ROOT")
+	if err != nil {
+		return nil, err
+	}
+	userPath, err := sherpa.GetEnvRequired("WLP_USER_DIR")
+	if err != nil {
+		return nil, err
+	}
+	serverNames, err := getServerNames()
+	if err != nil {
+		return nil, err
+	}
+
+	stackUserPath := filepath.Join(runtimePath, "usr")
+	if linked, err := isLinkedTo(stackUserPath, userPath); err != nil {
+		return nil, err
+	} else if !linked {
+		s.Logger.Debugf("Linking %s to %s", stackUserPath, userPath)
+		if err := server.SetUserDirectory(userPath, stackUserPath, serverNames); err != nil {
+			return nil, fmt.Errorf("unable to set user directory of stack runtime\n%w", err)
+		}
+	}
+
+	available, err := server.GetRuntimeFeatures(runtimePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get features of stack runtime\n%w", err)
+	}
+	for _, serverName := range serverNames {
+		required, err := server.GetFeatureList(nil, filepath.Join(userPath, "servers", serverName), nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get features of server %s\n%w", serverName, err)
+		}
+		if missing := server.MissingFeatures(required, available); len(missing) > 0 {
+			return nil, fmt.Errorf("the Liberty runtime in %s does not contain the features required by server %s: %s; "+
+				"use a run image that includes them or set BP_LIBERTY_INSTALL_TYPE to install a runtime",
+				runtimePath, serverName, strings.Join(missing, ", "))
+		}
+	}
+
+	return nil, nil
+}
+
+// isLinkedTo returns true if path already resolves to target, as when the container is restarted
+func isLinkedTo(path string, target string) (bool, error) {
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false, nil
+	}
+	resolvedTarget, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return false, fmt.Errorf("unable to resolve %s\n%w", target, err)
+	}
+	return resolvedPath == resolvedTarget, nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/helper"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testStack(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		configurer  helper.StackRuntimeConfigurer
+		runtimePath string
+		userDir     string
+	)
+
+	it.Before(func() {
+		var err error
+
+		runtimePath, err = os.MkdirTemp("", "execd-helper-stack-runtime")
+		Expect(err).NotTo(HaveOccurred())
+		runtimePath, err = filepath.EvalSymlinks(runtimePath)
+		Expect(err).NotTo(HaveOccurred())
+		stackDefaults := filepath.Join(runtimePath, "usr", "servers", "defaultServer", "configDropins", "defaults")
+		Expect(os.MkdirAll(stackDefaults, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(stackDefaults, "keystore.xml"), []byte("<server/>"), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(runtimePath, "lib", "features"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "lib", "features", "servlet-6.0.mf"), []byte("IBM-ShortName: servlet-6.0\n"), 0644)).To(Succeed())
+
+		userDir, err = os.MkdirTemp("", "execd-helper-stack-usr")
+		Expect(err).NotTo(HaveOccurred())
+		userDir, err = filepath.EvalSymlinks(userDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(userDir, "servers", "testServer"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(userDir, "servers", "testServer", "server.xml"), []byte(`<server>
+  <featureManager>
+    <feature>servlet-6.0</feature>
+    <feature>usr:custom-1.0</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+
+		Expect(os.Setenv("BPI_LIBERTY_STACK_RUNTIME", "true")).To(Succeed())
+		Expect(os.Setenv("BPI_LIBERTY_RUNTIME_ROOT", runtimePath)).To(Succeed())
+		Expect(os.Setenv("WLP_USER_DIR", userDir)).To(Succeed())
+		Expect(os.Setenv("BPI_LIBERTY_SERVER_NAME", "testServer")).To(Succeed())
+
+		configurer = helper.StackRuntimeConfigurer{Logger: bard.NewLogger(io.Discard)}
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("BPI_LIBERTY_STACK_RUNTIME")).To(Succeed())
+		Expect(os.Unsetenv("BPI_LIBERTY_RUNTIME_ROOT")).To(Succeed())
+		Expect(os.Unsetenv("WLP_USER_DIR")).To(Succeed())
+		Expect(os.Unsetenv("BPI_LIBERTY_SERVER_NAME")).To(Succeed())
+		Expect(os.Unsetenv("BPI_LIBERTY_SERVER_NAMES")).To(Succeed())
+		Expect(os.RemoveAll(runtimePath)).To(Succeed())
+		Expect(os.RemoveAll(userDir)).To(Succeed())
+	})
+
+	it("does nothing for other runtimes", func() {
+		Expect(os.Unsetenv("BPI_LIBERTY_STACK_RUNTIME")).To(Succeed())
+
+		_, err := configurer.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(runtimePath, "usr")).To(BeADirectory())
+		Expect(filepath.Join(userDir, "servers", "testServer", "configDropins")).NotTo(BeADirectory())
+	})
+
+	it("links the user directory into the stack runtime", func() {
+		_, err := configurer.Execute()
+		Expect(err).NotTo(HaveOccurred())
+
+		target, err := os.Readlink(filepath.Join(runtimePath, "usr"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target).To(Equal(userDir))
+		Expect(filepath.Join(userDir, "servers", "testServer", "configDropins", "defaults", "keystore.xml")).To(BeARegularFile())
+
+		_, err = configurer.Execute()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it("fails if a required feature is missing from the stack runtime", func() {
+		Expect(os.MkdirAll(filepath.Join(userDir, "servers", "testServer", "configDropins", "overrides"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(userDir, "servers", "testServer", "configDropins", "overrides", "features.xml"), []byte(`<server>
+  <featureManager>
+    <feature>jdbc-4.3</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+
+		_, err := configurer.Execute()
+		Expect(err).To(MatchError(ContainSubstring("does not contain the features required by server testServer: jdbc-4.3")))
+	})
+
+	context("building several servers", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(userDir, "servers", "otherServer"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(userDir, "servers", "otherServer", "server.xml"), []byte(`<server>
+  <featureManager>
+    <feature>servlet-6.0</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+			Expect(os.Setenv("BPI_LIBERTY_SERVER_NAMES", "testServer otherServer")).To(Succeed())
+		})
+
+		it("configures every server", func() {
+			_, err := configurer.Execute()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(userDir, "servers", "testServer", "configDropins", "defaults", "keystore.xml")).To(BeARegularFile())
+			Expect(filepath.Join(userDir, "servers", "otherServer", "configDropins", "defaults", "keystore.xml")).To(BeARegularFile())
+		})
+
+		it("checks the features of every server", func() {
+			Expect(os.WriteFile(filepath.Join(userDir, "servers", "otherServer", "server.xml"), []byte(`<server>
+  <featureManager>
+    <feature>jdbc-4.3</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+
+			_, err := configurer.Execute()
+			Expect(err).To(MatchError(ContainSubstring("does not contain the features required by server otherServer: jdbc-4.3")))
+		})
+	})
+}
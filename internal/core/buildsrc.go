@@ -19,7 +19,11 @@ package core
 import (
 	"fmt"
 	"github.com/paketo-buildpacks/libpak/sherpa"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
 
 	"github.com/paketo-buildpacks/liberty/internal/server"
 	"github.com/paketo-buildpacks/liberty/internal/util"
@@ -27,9 +31,15 @@ import (
 )
 
 const (
-	JavaAppServerLiberty  = "liberty"
-	AppBuildSourceName    = "app-build-src"
-	ServerBuildSourceName = "svr-build-src"
+	JavaAppServerLiberty      = "liberty"
+	AppBuildSourceName        = "app-build-src"
+	ServerBuildSourceName     = "svr-build-src"
+	SpringBootBuildSourceName = "spring-boot-build-src"
+
+	// AllServers selects every server of a packaged server when used as the server name
+	AllServers = "*"
+	// DefaultServer is the name of the server that Liberty uses when no server name is given
+	DefaultServer = "defaultServer"
 )
 
 // BuildSource represents different build sources that the Liberty buildpack supports
@@ -121,19 +131,111 @@ func (a AppBuildSource) AppPath() (string, error) {
 	return a.Root, nil
 }
 
+// SpringBootBuildSource is used when building a Spring Boot application. Since Spring Boot applications are usually
+// run with their embedded server, it is only used if Liberty is requested with `BP_JAVA_APP_SERVER`.
+type SpringBootBuildSource struct {
+	RequestedAppServer string
+	Root               string
+	Logger             bard.Logger
+}
+
+func NewSpringBootBuildSource(appPath string, requestedAppServer string, logger bard.Logger) SpringBootBuildSource {
+	return SpringBootBuildSource{
+		RequestedAppServer: requestedAppServer,
+		Root:               appPath,
+		Logger:             logger,
+	}
+}
+
+func (s SpringBootBuildSource) Name() string {
+	return SpringBootBuildSourceName
+}
+
+// Detect checks that Liberty was requested and `Spring-Boot-Version` is defined in `META-INF/MANIFEST.MF`
+func (s SpringBootBuildSource) Detect() (bool, error) {
+	version, err := s.Version()
+	if err != nil {
+		return false, err
+	}
+	if version == "" {
+		return false, nil
+	}
+
+	if s.RequestedAppServer != JavaAppServerLiberty {
+		s.Logger.Debugf("Spring Boot application found but BP_JAVA_APP_SERVER is not set to '%s'", JavaAppServerLiberty)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (s SpringBootBuildSource) DefaultServerName() (string, error) {
+	return "defaultServer", nil
+}
+
+func (s SpringBootBuildSource) ValidateApp() (bool, error) {
+	version, err := s.Version()
+	if err != nil {
+		return false, err
+	}
+	return version != "", nil
+}
+
+func (s SpringBootBuildSource) AppPath() (string, error) {
+	return s.Root, nil
+}
+
+// Version returns the Spring Boot version the application was built with
+func (s SpringBootBuildSource) Version() (string, error) {
+	return util.GetSpringBootVersion(s.Root)
+}
+
+// Features returns the Liberty features required to run the application based on its Spring Boot version
+func (s SpringBootBuildSource) Features() ([]string, error) {
+	version, err := s.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major := strings.SplitN(version, ".", 2)[0]; major {
+	case "1":
+		return []string{"springBoot-1.5", "servlet-3.1"}, nil
+	case "2":
+		return []string{"springBoot-2.0", "servlet-4.0"}, nil
+	case "3":
+		return []string{"springBoot-3.0", "servlet-6.0"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported Spring Boot version '%s'", version)
+	}
+}
+
 // ServerBuildSource is used when building a packaged server or Liberty server directory
 type ServerBuildSource struct {
 	// InstallRoot is the Liberty installation directory where `wlp` or `usr` is found
 	InstallRoot string
-	// ServerName is the serve instance that built
+	// ServerName is the serve instance that built, which is the first of ServerNames
 	ServerName string
-	Logger     bard.Logger
+	// ServerNames are the requested server instances, or AllServers to build every server
+	ServerNames []string
+	Logger      bard.Logger
 }
 
-func NewServerBuildSource(installRoot string, serverName string, logger bard.Logger) ServerBuildSource {
+// NewServerBuildSource creates a build source for the servers named in serverNames, which is a list of server names
+// separated by commas or whitespace, or AllServers.
+func NewServerBuildSource(installRoot string, serverNames string, logger bard.Logger) ServerBuildSource {
+	names := strings.FieldsFunc(serverNames, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	var serverName string
+	if len(names) > 0 && names[0] != AllServers {
+		serverName = names[0]
+	}
+
 	return ServerBuildSource{
 		InstallRoot: installRoot,
 		ServerName:  serverName,
+		ServerNames: names,
 		Logger:      logger,
 	}
 }
@@ -149,7 +251,16 @@ func (s ServerBuildSource) Detect() (bool, error) {
 	}
 
 	if serverPath == "" {
-		return false, nil
+		pkg, found, err := s.Package()
+		if err != nil || !found {
+			return false, err
+		}
+		for _, serverName := range s.ServerNames {
+			if serverName != AllServers && !slices.Contains(pkg.Servers(), serverName) {
+				return false, nil
+			}
+		}
+		return true, nil
 	}
 
 	return sherpa.FileExists(filepath.Join(serverPath, "server.xml"))
@@ -168,6 +279,9 @@ func (s ServerBuildSource) DefaultServerName() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if s.IsAllServers() && len(servers) > 0 {
+		return orderServers(servers)[0], nil
+	}
 	if numServers := len(servers); numServers == 0 {
 		return "", fmt.Errorf("unable to determine which server to use -- no servers detected")
 	} else if numServers > 1 {
@@ -176,13 +290,60 @@ func (s ServerBuildSource) DefaultServerName() (string, error) {
 	return servers[0], nil
 }
 
+// IsAllServers returns true if every server should be built.
+func (s ServerBuildSource) IsAllServers() bool {
+	return slices.Contains(s.ServerNames, AllServers)
+}
+
+// Servers returns the names of the servers to build. The first server is the default one, which is the first server
+// requested or, when building all servers, `defaultServer` if present.
+func (s ServerBuildSource) Servers() ([]string, error) {
+	userPath, err := s.UserPath()
+	if err != nil || userPath == "" {
+		return nil, fmt.Errorf("unable to find usr directory\n%w", err)
+	}
+
+	if s.IsAllServers() {
+		servers, err := server.GetServerList(userPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list servers\n%w", err)
+		}
+		if len(servers) == 0 {
+			return nil, fmt.Errorf("unable to determine which server to use -- no servers detected")
+		}
+		return orderServers(servers), nil
+	}
+
+	if len(s.ServerNames) == 0 {
+		serverName, err := s.DefaultServerName()
+		if err != nil {
+			return nil, err
+		}
+		return []string{serverName}, nil
+	}
+
+	var servers []string
+	for _, serverName := range s.ServerNames {
+		if slices.Contains(servers, serverName) {
+			continue
+		}
+		if exists, err := sherpa.FileExists(filepath.Join(userPath, "servers", serverName, "server.xml")); err != nil {
+			return nil, fmt.Errorf("unable to check server %s\n%w", serverName, err)
+		} else if !exists {
+			return nil, fmt.Errorf("unable to find server %s in %s", serverName, userPath)
+		}
+		servers = append(servers, serverName)
+	}
+	return servers, nil
+}
+
 func (s ServerBuildSource) ValidateApp() (bool, error) {
 	serverPath, err := s.ServerPath()
 	if err != nil {
 		return false, fmt.Errorf("unable to validate packaged server app\n%w", err)
 	}
 	if serverPath == "" {
-		return false, nil
+		return s.packageHasInstalledApps()
 	}
 
 	return server.HasInstalledApps(serverPath)
@@ -238,3 +399,69 @@ func (s ServerBuildSource) UserPath() (string, error) {
 
 	return "", nil
 }
+
+// Package returns the `server package` archive in the install root if the server has not been extracted yet.
+func (s ServerBuildSource) Package() (util.ServerPackage, bool, error) {
+	if userPath, err := s.UserPath(); err != nil || userPath != "" {
+		return util.ServerPackage{}, false, err
+	}
+
+	pkg, found, err := util.FindServerPackage(s.InstallRoot)
+	if err != nil {
+		return util.ServerPackage{}, false, fmt.Errorf("unable to find server package\n%w", err)
+	}
+	return pkg, found, nil
+}
+
+// ExtractPackage extracts the `server package` archive in the install root, after which the server is built like an
+// expanded one. The archive is removed once extracted. Returns false if there is no server package.
+func (s ServerBuildSource) ExtractPackage() (bool, error) {
+	pkg, found, err := s.Package()
+	if err != nil || !found {
+		return false, err
+	}
+
+	s.Logger.Bodyf("Extracting server package %s", filepath.Base(pkg.Path))
+	if err := pkg.Extract(s.InstallRoot); err != nil {
+		return false, fmt.Errorf("unable to extract server package\n%w", err)
+	}
+	if err := os.Remove(pkg.Path); err != nil {
+		return false, fmt.Errorf("unable to remove server package\n%w", err)
+	}
+	return true, nil
+}
+
+func (s ServerBuildSource) packageHasInstalledApps() (bool, error) {
+	pkg, found, err := s.Package()
+	if err != nil || !found {
+		return false, err
+	}
+
+	serverName := s.ServerName
+	if serverName == "" {
+		servers := pkg.Servers()
+		if s.IsAllServers() && len(servers) > 0 {
+			servers = orderServers(servers)
+		} else if len(servers) != 1 {
+			return false, nil
+		}
+		serverName = servers[0]
+	}
+	return pkg.HasInstalledApps(serverName), nil
+}
+
+// orderServers sorts the servers with `defaultServer` first, as that is the one Liberty runs when not given a name
+func orderServers(servers []string) []string {
+	ordered := slices.Clone(servers)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		if a == b {
+			return 0
+		} else if a == DefaultServer {
+			return -1
+		} else if b == DefaultServer {
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return ordered
+}
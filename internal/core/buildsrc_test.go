@@ -17,6 +17,7 @@
 package core_test
 
 import (
+	"archive/zip"
 	"io"
 	"os"
 	"path/filepath"
@@ -213,6 +214,63 @@ func testBuildSource(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 
+		when("several servers are requested", func() {
+			it.Before(func() {
+				for _, name := range []string{"stubServer", "defaultServer", "mainServer"} {
+					serverPath := filepath.Join(testPath, "wlp", "usr", "servers", name)
+					Expect(os.MkdirAll(serverPath, 0755)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte{}, 0644)).To(Succeed())
+				}
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(filepath.Join(testPath, "wlp"))).To(Succeed())
+			})
+
+			it("parses a list of server names", func() {
+				serverBuildSource := core.NewServerBuildSource(testPath, "mainServer, stubServer mainServer", bard.NewLogger(io.Discard))
+				Expect(serverBuildSource.ServerName).To(Equal("mainServer"))
+				Expect(serverBuildSource.IsAllServers()).To(BeFalse())
+
+				ok, err := serverBuildSource.Detect()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+
+				servers, err := serverBuildSource.Servers()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(servers).To(Equal([]string{"mainServer", "stubServer"}))
+			})
+
+			it("fails if a requested server does not exist", func() {
+				serverBuildSource := core.NewServerBuildSource(testPath, "mainServer,otherServer", bard.NewLogger(io.Discard))
+				_, err := serverBuildSource.Servers()
+				Expect(err).To(MatchError(ContainSubstring("unable to find server otherServer")))
+			})
+
+			it("selects all servers with defaultServer first", func() {
+				serverBuildSource := core.NewServerBuildSource(testPath, "*", bard.NewLogger(io.Discard))
+				Expect(serverBuildSource.ServerName).To(BeEmpty())
+				Expect(serverBuildSource.IsAllServers()).To(BeTrue())
+
+				servers, err := serverBuildSource.Servers()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(servers).To(Equal([]string{"defaultServer", "mainServer", "stubServer"}))
+
+				serverPath, err := serverBuildSource.ServerPath()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(serverPath).To(Equal(filepath.Join(testPath, "wlp", "usr", "servers", "defaultServer")))
+			})
+
+			it("uses the single server when no server name is set", func() {
+				Expect(os.RemoveAll(filepath.Join(testPath, "wlp", "usr", "servers", "defaultServer"))).To(Succeed())
+				Expect(os.RemoveAll(filepath.Join(testPath, "wlp", "usr", "servers", "stubServer"))).To(Succeed())
+
+				servers, err := core.NewServerBuildSource(testPath, "", bard.NewLogger(io.Discard)).Servers()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(servers).To(Equal([]string{"mainServer"}))
+			})
+		})
+
 		when("usr is provided", func() {
 			var serversPath string
 
@@ -315,6 +373,73 @@ func testBuildSource(t *testing.T, when spec.G, it spec.S) {
 				Expect(err).To(HaveOccurred())
 			})
 		})
+
+		when("a server package is provided", func() {
+			it.Before(func() {
+				out, err := os.Create(filepath.Join(testPath, "server.zip"))
+				Expect(err).NotTo(HaveOccurred())
+				writer := zip.NewWriter(out)
+				for _, name := range []string{"wlp/usr/servers/defaultServer/server.xml", "wlp/usr/servers/defaultServer/apps/app.war"} {
+					_, err := writer.Create(name)
+					Expect(err).NotTo(HaveOccurred())
+				}
+				Expect(writer.Close()).To(Succeed())
+				Expect(out.Close()).To(Succeed())
+			})
+
+			it("detects and validates the packaged server without extracting it", func() {
+				serverBuildSource := core.NewServerBuildSource(testPath, "", bard.NewLogger(io.Discard))
+				ok, err := serverBuildSource.Detect()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+
+				ok, err = serverBuildSource.ValidateApp()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				Expect(filepath.Join(testPath, "wlp")).ToNot(BeADirectory())
+			})
+
+			it("does not detect if the server name is not in the package", func() {
+				serverBuildSource := core.NewServerBuildSource(testPath, "testServer", bard.NewLogger(io.Discard))
+				ok, err := serverBuildSource.Detect()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+
+			it("detects when all servers are requested", func() {
+				serverBuildSource := core.NewServerBuildSource(testPath, "*", bard.NewLogger(io.Discard))
+				ok, err := serverBuildSource.Detect()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+
+				ok, err = serverBuildSource.ValidateApp()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+			})
+
+			it("does not detect if one of the requested servers is not in the package", func() {
+				serverBuildSource := core.NewServerBuildSource(testPath, "defaultServer,testServer", bard.NewLogger(io.Discard))
+				ok, err := serverBuildSource.Detect()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeFalse())
+			})
+
+			it("extracts the package", func() {
+				serverBuildSource := core.NewServerBuildSource(testPath, "", bard.NewLogger(io.Discard))
+				extracted, err := serverBuildSource.ExtractPackage()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(extracted).To(BeTrue())
+				Expect(filepath.Join(testPath, "server.zip")).ToNot(BeAnExistingFile())
+
+				serverPath, err := serverBuildSource.ServerPath()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(serverPath).To(Equal(filepath.Join(testPath, "wlp", "usr", "servers", "defaultServer")))
+
+				extracted, err = serverBuildSource.ExtractPackage()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(extracted).To(BeFalse())
+			})
+		})
 	})
 
 	when("building an app source with server config", func() {
@@ -326,4 +451,66 @@ func testBuildSource(t *testing.T, when spec.G, it spec.S) {
 			Expect(ok).To(BeTrue())
 		})
 	})
+	when("building a Spring Boot source", func() {
+		it.Before(func() {
+			Expect(os.Mkdir(filepath.Join(testPath, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(testPath, "META-INF", "MANIFEST.MF"),
+				[]byte("Main-Class: org.springframework.boot.loader.JarLauncher\nSpring-Boot-Version: 2.7.18\n"),
+				0644)).To(Succeed())
+		})
+
+		it("detects when Liberty is requested", func() {
+			springBootBuildSrc := core.NewSpringBootBuildSource(testPath, "liberty", bard.NewLogger(io.Discard))
+			ok, err := springBootBuildSrc.Detect()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ok, err = springBootBuildSrc.ValidateApp()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		it("does not detect when Liberty is not requested", func() {
+			springBootBuildSrc := core.NewSpringBootBuildSource(testPath, "", bard.NewLogger(io.Discard))
+			ok, err := springBootBuildSrc.Detect()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		it("does not detect other applications", func() {
+			Expect(os.WriteFile(filepath.Join(testPath, "META-INF", "MANIFEST.MF"),
+				[]byte("Main-Class: com.java.HelloWorld"),
+				0644)).To(Succeed())
+			springBootBuildSrc := core.NewSpringBootBuildSource(testPath, "liberty", bard.NewLogger(io.Discard))
+			ok, err := springBootBuildSrc.Detect()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		it("returns the features for Spring Boot 2", func() {
+			springBootBuildSrc := core.NewSpringBootBuildSource(testPath, "liberty", bard.NewLogger(io.Discard))
+			features, err := springBootBuildSrc.Features()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(features).To(Equal([]string{"springBoot-2.0", "servlet-4.0"}))
+		})
+
+		it("returns the features for Spring Boot 3", func() {
+			Expect(os.WriteFile(filepath.Join(testPath, "META-INF", "MANIFEST.MF"),
+				[]byte("Spring-Boot-Version: 3.3.0\n"),
+				0644)).To(Succeed())
+			springBootBuildSrc := core.NewSpringBootBuildSource(testPath, "liberty", bard.NewLogger(io.Discard))
+			features, err := springBootBuildSrc.Features()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(features).To(Equal([]string{"springBoot-3.0", "servlet-6.0"}))
+		})
+
+		it("fails for unsupported Spring Boot versions", func() {
+			Expect(os.WriteFile(filepath.Join(testPath, "META-INF", "MANIFEST.MF"),
+				[]byte("Spring-Boot-Version: 4.0.0\n"),
+				0644)).To(Succeed())
+			springBootBuildSrc := core.NewSpringBootBuildSource(testPath, "liberty", bard.NewLogger(io.Discard))
+			_, err := springBootBuildSrc.Features()
+			Expect(err).To(MatchError("unsupported Spring Boot version '4.0.0'"))
+		})
+	})
 }
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// featureNamePattern matches feature names, such as `servlet-6.0` or `mpHealth-4.x`, in featureUtility messages
+var featureNamePattern = regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9.]*-\d+(?:\.(?:\d+|x))*\b`)
+
+// featureInstallCauses are the known causes of featureUtility failures, checked in order. The message ids identify the
+// cause, and the patterns match messages of other versions of featureUtility.
+var featureInstallCauses = []struct {
+	Ids     []string
+	Pattern *regexp.Regexp
+	Cause   string
+	Fix     string
+}{
+	{
+		Ids:     []string{"CWWKF1390E", "CWWKF1219E", "CWWKF1271E"},
+		Pattern: regexp.MustCompile(`(?i)repository.*(reach|connect|access)|connection|network|proxy|timed out`),
+		Cause:   "the feature repository could not be reached",
+		Fix: "Check that the build can reach Maven Central or the repository set in featureUtility.properties, " +
+			"including any proxy settings. Alternatively, set BP_LIBERTY_PROFILE to a profile containing the features.",
+	},
+	{
+		Ids:     []string{"CWWKF1258E", "CWWKF1385E"},
+		Pattern: regexp.MustCompile(`(?i)licen[cs]e`),
+		Cause:   "the license of the features does not allow them to be installed",
+		Fix: "The features require another edition of WebSphere Liberty. Set BP_LIBERTY_INSTALL_TYPE to a runtime " +
+			"that includes them, or remove them from the server configuration.",
+	},
+	{
+		Ids:     []string{"CWWKF0033E", "CWWKF0043E", "CWWKF0044E"},
+		Pattern: regexp.MustCompile(`(?i)conflict|cannot be loaded at the same time`),
+		Cause:   "the features conflict with each other",
+		Fix: "Enable only one version of each feature. Features of different Java EE or Jakarta EE versions cannot " +
+			"be used together; pick the features of a single version.",
+	},
+	{
+		Ids:     []string{"CWWKF1203E", "CWWKF1299E", "CWWKF1402E"},
+		Pattern: regexp.MustCompile(`(?i)unable to (obtain|resolve|find)|could not be (obtained|found|resolved)|not valid`),
+		Cause:   "the features could not be found",
+		Fix: "Check the spelling and version of the features, and that they are available for the Liberty " +
+			"version and install type. Custom features must be provided with a feature descriptor.",
+	},
+}
+
+// ConfiguredFeature is a feature named in a featureUtility message, along with the server configuration file enabling it
+type ConfiguredFeature struct {
+	Name       string
+	ConfigFile string
+}
+
+func (f ConfiguredFeature) String() string {
+	if f.ConfigFile == "" {
+		return f.Name
+	}
+	return fmt.Sprintf("%s (in %s)", f.Name, f.ConfigFile)
+}
+
+// FeatureInstallProblem is an error reported by featureUtility. The cause and fix are only set for known errors.
+type FeatureInstallProblem struct {
+	Message  Message
+	Features []ConfiguredFeature
+	Cause    string
+	Fix      string
+}
+
+func (p FeatureInstallProblem) String() string {
+	var lines []string
+	lines = append(lines, p.Message.Text)
+	if p.Cause != "" {
+		lines = append(lines, fmt.Sprintf("Cause: %s", p.Cause))
+	}
+	if len(p.Features) > 0 {
+		var features []string
+		for _, feature := range p.Features {
+			features = append(features, feature.String())
+		}
+		lines = append(lines, fmt.Sprintf("Features: %s", strings.Join(features, ", ")))
+	}
+	if p.Fix != "" {
+		lines = append(lines, fmt.Sprintf("Fix: %s", p.Fix))
+	}
+	return strings.Join(lines, "\n  ")
+}
+
+// DiagnoseFeatureInstall returns the problems in the output of a failed featureUtility run. The features named in each
+// message are located in the configuration of the server.
+func DiagnoseFeatureInstall(output string, serverPath string) ([]FeatureInstallProblem, error) {
+	featureConfigs, err := getFeatureConfigs(serverPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []FeatureInstallProblem
+	for _, line := range strings.Split(output, "\n") {
+		match := messageIdPattern.FindStringSubmatchIndex(line)
+		if match == nil || !strings.HasSuffix(line[match[2]:match[3]], "E") {
+			continue
+		}
+		message := Message{Id: line[match[2]:match[3]], Text: strings.TrimSpace(line[match[2]:])}
+
+		problem := FeatureInstallProblem{Message: message}
+		problem.Cause, problem.Fix = diagnoseFeatureInstallCause(message)
+		for _, name := range featureNamePattern.FindAllString(message.Text, -1) {
+			if slices.ContainsFunc(problem.Features, func(f ConfiguredFeature) bool { return f.Name == name }) {
+				continue
+			}
+			problem.Features = append(problem.Features, ConfiguredFeature{
+				Name:       name,
+				ConfigFile: featureConfigs[strings.ToLower(name)],
+			})
+		}
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+// diagnoseFeatureInstallCause returns the cause and fix of a featureUtility message. The message id is matched against
+// every cause first, as the text of a message may match the pattern of another cause, such as a feature that cannot be
+// found because the repository is not reachable. The patterns are only used for message ids that are not known.
+func diagnoseFeatureInstallCause(message Message) (string, string) {
+	for _, cause := range featureInstallCauses {
+		if slices.Contains(cause.Ids, message.Id) {
+			return cause.Cause, cause.Fix
+		}
+	}
+	for _, cause := range featureInstallCauses {
+		if cause.Pattern.MatchString(message.Text) {
+			return cause.Cause, cause.Fix
+		}
+	}
+	return "", ""
+}
+
+// getFeatureConfigs returns the configuration file, relative to the server directory, that first enables each feature,
+// keyed by the lower case feature name as feature names are not case sensitive
+func getFeatureConfigs(serverPath string) (map[string]string, error) {
+	configs, err := GetServerConfigs(serverPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get server configs\n%w", err)
+	}
+
+	featureConfigs := map[string]string{}
+	for _, configPath := range configs {
+		config, err := ReadServerConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read config\n%w", err)
+		}
+		relPath, err := filepath.Rel(serverPath, configPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get relative path of %s\n%w", configPath, err)
+		}
+		for _, feature := range config.FeatureManager.Features {
+			name := strings.ToLower(strings.TrimSpace(feature))
+			if _, found := featureConfigs[name]; !found {
+				featureConfigs[name] = relPath
+			}
+		}
+	}
+	return featureConfigs, nil
+}
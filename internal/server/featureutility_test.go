@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testFeatureUtility(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect     = NewWithT(t).Expect
+		serverPath string
+	)
+
+	it.Before(func() {
+		var err error
+		serverPath, err = os.MkdirTemp("", "featureutility")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte(`<server>
+  <featureManager>
+    <feature>servlet-2.x</feature>
+    <feature>jsp-2.3</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(serverPath, "configDropins", "overrides"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(serverPath, "configDropins", "overrides", "features.xml"), []byte(`<server>
+  <featureManager>
+    <feature>Pages-3.1</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(serverPath)).To(Succeed())
+	})
+
+	it("diagnoses features that cannot be found", func() {
+		problems, err := server.DiagnoseFeatureInstall(`Initializing ...
+Using 8 threads to download artifacts.
+CWWKF1299E: Unable to obtain the following features: servlet-2.x. Ensure that the features are valid for Open Liberty.
+`, serverPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Message.Id).To(Equal("CWWKF1299E"))
+		Expect(problems[0].Cause).To(Equal("the features could not be found"))
+		Expect(problems[0].Features).To(Equal([]server.ConfiguredFeature{{Name: "servlet-2.x", ConfigFile: "server.xml"}}))
+		Expect(problems[0].String()).To(ContainSubstring("Features: servlet-2.x (in server.xml)"))
+		Expect(problems[0].String()).To(ContainSubstring("Fix: Check the spelling and version of the features"))
+	})
+
+	it("diagnoses known message ids before matching the text of other causes", func() {
+		problems, err := server.DiagnoseFeatureInstall(
+			"CWWKF1299E: Unable to obtain the following features: servlet-2.x. Check the network connection.", serverPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Cause).To(Equal("the features could not be found"))
+	})
+
+	it("diagnoses conflicting features", func() {
+		problems, err := server.DiagnoseFeatureInstall(
+			"CWWKF0033E: The singleton features jsp-2.3 and pages-3.1 cannot be loaded at the same time.", serverPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Cause).To(Equal("the features conflict with each other"))
+		Expect(problems[0].Features).To(Equal([]server.ConfiguredFeature{
+			{Name: "jsp-2.3", ConfigFile: "server.xml"},
+			{Name: "pages-3.1", ConfigFile: filepath.Join("configDropins", "overrides", "features.xml")},
+		}))
+	})
+
+	it("diagnoses repository failures by the message text", func() {
+		problems, err := server.DiagnoseFeatureInstall(
+			"CWWKF9999E: The repository https://repo.maven.apache.org/maven2 cannot be reached.", serverPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Cause).To(Equal("the feature repository could not be reached"))
+		Expect(problems[0].Features).To(BeEmpty())
+	})
+
+	it("diagnoses license failures", func() {
+		problems, err := server.DiagnoseFeatureInstall(
+			"CWWKF1385E: The license for the feature collectiveController-1.0 is not accepted by this edition.", serverPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].Cause).To(Equal("the license of the features does not allow them to be installed"))
+		Expect(problems[0].Features).To(Equal([]server.ConfiguredFeature{{Name: "collectiveController-1.0"}}))
+	})
+
+	it("keeps unknown errors without a cause and ignores other messages", func() {
+		problems, err := server.DiagnoseFeatureInstall(`CWWKF1216I: Downloading features.
+CWWKF9998E: Something unexpected happened.`, serverPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems).To(Equal([]server.FeatureInstallProblem{
+			{Message: server.Message{Id: "CWWKF9998E", Text: "CWWKF9998E: Something unexpected happened."}},
+		}))
+		Expect(problems[0].String()).To(Equal("CWWKF9998E: Something unexpected happened."))
+	})
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+const (
+	// IFixPolicySkip skips iFixes that do not apply to the runtime version with a warning
+	IFixPolicySkip = "skip"
+	// IFixPolicyFail fails the build if an iFix does not apply to the runtime version
+	IFixPolicyFail = "fail"
+)
+
+// fixDescriptor matches the fix XML of an iFix archive, e.g. `lib/fixes/210012-wlp-archive-IFPH12345_21.0.0012.xml`
+var fixDescriptor = regexp.MustCompile(`(?:^|/)lib/fixes/[^/]+\.xml$`)
+
+// IFixInfo is the metadata of an iFix read from the fix XML in its archive.
+type IFixInfo struct {
+	Id string
+	// APARs are the problems that the iFix resolves, e.g. `PH12345`
+	APARs []string
+	// Offerings are the products and runtime versions the iFix applies to
+	Offerings []IFixOffering
+}
+
+// IFixOffering is a product that an iFix applies to.
+type IFixOffering struct {
+	// Id is the product ID, `io.openliberty` or `com.ibm.websphere.appserver`
+	Id string
+	// Tolerance is the runtime versions of the product the iFix applies to, either a single version or a range such as
+	// `[21.0.0.9,21.0.0.12]`
+	Tolerance string
+}
+
+type fixXML struct {
+	Id            string `xml:"id,attr"`
+	Applicability struct {
+		Offerings []struct {
+			Id        string `xml:"id,attr"`
+			Tolerance string `xml:"tolerance,attr"`
+		} `xml:"offering"`
+	} `xml:"applicability"`
+	Resolves struct {
+		Problems []struct {
+			DisplayId string `xml:"displayId,attr"`
+		} `xml:"problem"`
+	} `xml:"resolves"`
+}
+
+// ReadIFixInfo reads the metadata of the iFix archive at the given path. Returns false if the archive does not contain
+// a fix XML. The `lafiles` of the archive are not read, as they only hold the license agreement in each language; the
+// APARs and applicable products and versions are only listed in the fix XML.
+func ReadIFixInfo(ifixPath string) (IFixInfo, bool, error) {
+	reader, err := zip.OpenReader(ifixPath)
+	if err != nil {
+		return IFixInfo{}, false, fmt.Errorf("unable to open %s\n%w", ifixPath, err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if !fixDescriptor.MatchString(file.Name) {
+			continue
+		}
+
+		in, err := file.Open()
+		if err != nil {
+			return IFixInfo{}, false, fmt.Errorf("unable to open %s\n%w", file.Name, err)
+		}
+		contents, err := io.ReadAll(in)
+		in.Close()
+		if err != nil {
+			return IFixInfo{}, false, fmt.Errorf("unable to read %s\n%w", file.Name, err)
+		}
+
+		var fix fixXML
+		if err := xml.Unmarshal(contents, &fix); err != nil {
+			return IFixInfo{}, false, fmt.Errorf("unable to parse %s\n%w", file.Name, err)
+		}
+
+		info := IFixInfo{Id: fix.Id}
+		for _, problem := range fix.Resolves.Problems {
+			info.APARs = append(info.APARs, problem.DisplayId)
+		}
+		for _, offering := range fix.Applicability.Offerings {
+			info.Offerings = append(info.Offerings, IFixOffering{Id: offering.Id, Tolerance: offering.Tolerance})
+		}
+		return info, true, nil
+	}
+	return IFixInfo{}, false, nil
+}
+
+// AppliesTo returns true if the iFix applies to the given product and runtime version. An iFix without offerings
+// applies to any product and version, as does an offering without an ID or tolerance to any product or version.
+func (i IFixInfo) AppliesTo(productId string, version string) bool {
+	if len(i.Offerings) == 0 {
+		return true
+	}
+	for _, offering := range i.Offerings {
+		if offering.Id != "" && offering.Id != productId {
+			continue
+		}
+		if offering.Tolerance == "" || versionInRange(version, offering.Tolerance) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeOfferings describes the offerings of the iFix, e.g. `com.ibm.websphere.appserver [24.0.0.4,24.0.0.6]`
+func (i IFixInfo) describeOfferings() string {
+	var offerings []string
+	for _, offering := range i.Offerings {
+		offerings = append(offerings, strings.TrimSpace(fmt.Sprintf("%s %s", offering.Id, offering.Tolerance)))
+	}
+	return strings.Join(offerings, ", ")
+}
+
+// SelectIFixes returns the iFixes that apply to the product and version of the runtime. iFixes that do not apply are
+// skipped with a warning or fail the build, according to the policy. iFixes without readable metadata are installed
+// with a warning, unless the policy is to fail.
+func SelectIFixes(ifixes []string, productId string, version string, policy string, logger bard.Logger) ([]string, error) {
+	version = NormalizeLibertyVersion(version)
+
+	var selected []string
+	for _, ifix := range ifixes {
+		info, found, err := ReadIFixInfo(ifix)
+		if err != nil || !found {
+			if policy == IFixPolicyFail {
+				if err == nil {
+					err = fmt.Errorf("no fix XML in lib/fixes")
+				}
+				return nil, fmt.Errorf("unable to determine which Liberty versions iFix %s applies to; remove it or set "+
+					"BP_LIBERTY_IFIX_POLICY to %s to install it anyway\n%w", ifix, IFixPolicySkip, err)
+			}
+			logger.Info(color.YellowString("Warning: Unable to determine which Liberty versions iFix %s applies to; it will be installed", ifix))
+			selected = append(selected, ifix)
+			continue
+		}
+
+		if info.AppliesTo(productId, version) {
+			logger.Debugf("iFix %s for %s applies to %s %s", info.Id, strings.Join(info.APARs, ", "), productId, version)
+			selected = append(selected, ifix)
+			continue
+		}
+
+		message := fmt.Sprintf("iFix %s for %s applies to %s, not %s %s",
+			info.Id, strings.Join(info.APARs, ", "), info.describeOfferings(), productId, version)
+		if policy == IFixPolicyFail {
+			return nil, fmt.Errorf("%s; remove it or set BP_LIBERTY_IFIX_POLICY to %s", message, IFixPolicySkip)
+		}
+		logger.Info(color.YellowString("Warning: Skipping %s", message))
+	}
+	return selected, nil
+}
+
+// versionInRange returns true if the version matches the tolerance, which is either a single version or an OSGi
+// version range such as `[21.0.0.9,21.0.0.12)`
+func versionInRange(version string, tolerance string) bool {
+	tolerance = strings.TrimSpace(tolerance)
+	if !strings.ContainsAny(tolerance, "[(") {
+		return compareVersions(version, tolerance) == 0
+	}
+
+	low, high, ok := strings.Cut(strings.Trim(tolerance, "[]()"), ",")
+	if !ok {
+		return false
+	}
+	lowCmp := compareVersions(version, strings.TrimSpace(low))
+	highCmp := compareVersions(version, strings.TrimSpace(high))
+
+	inLow := lowCmp > 0 || (lowCmp == 0 && strings.HasPrefix(tolerance, "["))
+	inHigh := highCmp < 0 || (highCmp == 0 && strings.HasSuffix(tolerance, "]"))
+	return inLow && inHigh
+}
+
+// compareVersions compares dotted numeric versions, treating missing segments as zero
+func compareVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart int
+		if i < len(aParts) {
+			aPart, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bPart, _ = strconv.Atoi(bParts[i])
+		}
+		if aPart != bPart {
+			if aPart < bPart {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testIFix(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect    = NewWithT(t).Expect
+		ifixesDir string
+	)
+
+	writeIFix := func(name string, productId string, tolerance string) string {
+		path := filepath.Join(ifixesDir, name)
+		out, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		writer := zip.NewWriter(out)
+		w, err := writer.Create("lib/fixes/" + name[:len(name)-len(".jar")] + ".xml")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<fix id="` + name[:len(name)-len(".jar")] + `" version="1.0.0">
+  <applicability>
+    <offering id="` + productId + `" tolerance="` + tolerance + `"/>
+  </applicability>
+  <resolves problemCount="1" description="This fix resolves APARS:" showList="true">
+    <problem id="com.ibm.ws.apar.PH12345" displayId="PH12345" description="Fix something"/>
+  </resolves>
+</fix>`))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = writer.Create("lafiles/LA_en")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		Expect(out.Close()).To(Succeed())
+		return path
+	}
+
+	it.Before(func() {
+		var err error
+		ifixesDir, err = os.MkdirTemp("", "ifixes")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ifixesDir)).To(Succeed())
+	})
+
+	it("reads the iFix metadata", func() {
+		path := writeIFix("240006-wlp-archive-IFPH12345.jar", server.WebSphereLibertyProductId, "[24.0.0.4,24.0.0.6]")
+
+		info, found, err := server.ReadIFixInfo(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(info).To(Equal(server.IFixInfo{
+			Id:        "240006-wlp-archive-IFPH12345",
+			APARs:     []string{"PH12345"},
+			Offerings: []server.IFixOffering{{Id: "com.ibm.websphere.appserver", Tolerance: "[24.0.0.4,24.0.0.6]"}},
+		}))
+	})
+
+	it("checks the versions an iFix applies to", func() {
+		offering := func(tolerance string) server.IFixInfo {
+			return server.IFixInfo{Offerings: []server.IFixOffering{{Id: server.OpenLibertyProductId, Tolerance: tolerance}}}
+		}
+		Expect(offering("24.0.0.6").AppliesTo(server.OpenLibertyProductId, "24.0.0.6")).To(BeTrue())
+		Expect(offering("24.0.0.6").AppliesTo(server.OpenLibertyProductId, "24.0.0.7")).To(BeFalse())
+		Expect(offering("[24.0.0.4,24.0.0.6]").AppliesTo(server.OpenLibertyProductId, "24.0.0.4")).To(BeTrue())
+		Expect(offering("[24.0.0.4,24.0.0.6)").AppliesTo(server.OpenLibertyProductId, "24.0.0.6")).To(BeFalse())
+		Expect(offering("(24.0.0.4,24.0.0.12]").AppliesTo(server.OpenLibertyProductId, "24.0.0.10")).To(BeTrue())
+		Expect(offering("(24.0.0.4,24.0.0.12]").AppliesTo(server.OpenLibertyProductId, "24.0.0.4")).To(BeFalse())
+		Expect(offering("").AppliesTo(server.OpenLibertyProductId, "24.0.0.4")).To(BeTrue())
+		Expect(server.IFixInfo{}.AppliesTo(server.OpenLibertyProductId, "24.0.0.4")).To(BeTrue())
+	})
+
+	it("checks the products an iFix applies to", func() {
+		info := server.IFixInfo{Offerings: []server.IFixOffering{{Id: server.WebSphereLibertyProductId, Tolerance: "24.0.0.6"}}}
+		Expect(info.AppliesTo(server.WebSphereLibertyProductId, "24.0.0.6")).To(BeTrue())
+		Expect(info.AppliesTo(server.OpenLibertyProductId, "24.0.0.6")).To(BeFalse())
+		Expect(server.IFixInfo{Offerings: []server.IFixOffering{{Tolerance: "24.0.0.6"}}}.AppliesTo(server.OpenLibertyProductId, "24.0.0.6")).To(BeTrue())
+	})
+
+	it("skips iFixes that do not apply", func() {
+		applies := writeIFix("240006-wlp-archive-IFPH12345.jar", server.WebSphereLibertyProductId, "24.0.0.6")
+		other := writeIFix("230012-wlp-archive-IFPH67890.jar", server.WebSphereLibertyProductId, "23.0.0.12")
+		unknown := filepath.Join(ifixesDir, "unknown.jar")
+		Expect(os.WriteFile(unknown, []byte{}, 0644)).To(Succeed())
+
+		ifixes, err := server.SelectIFixes([]string{applies, other, unknown}, server.WebSphereLibertyProductId, "24.0.0.6", server.IFixPolicySkip, bard.NewLogger(io.Discard))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ifixes).To(Equal([]string{applies, unknown}))
+	})
+
+	it("fails for iFixes that do not apply", func() {
+		other := writeIFix("230012-wlp-archive-IFPH67890.jar", server.WebSphereLibertyProductId, "23.0.0.12")
+
+		_, err := server.SelectIFixes([]string{other}, server.WebSphereLibertyProductId, "24.0.0.6", server.IFixPolicyFail, bard.NewLogger(io.Discard))
+		Expect(err).To(MatchError(ContainSubstring("iFix 230012-wlp-archive-IFPH67890 for PH12345 applies to com.ibm.websphere.appserver 23.0.0.12, not com.ibm.websphere.appserver 24.0.0.6")))
+	})
+
+	it("selects iFixes for versions in the form used by the buildpack", func() {
+		applies := writeIFix("240006-wlp-archive-IFPH12345.jar", server.WebSphereLibertyProductId, "24.0.0.6")
+
+		ifixes, err := server.SelectIFixes([]string{applies}, server.WebSphereLibertyProductId, "24.0.6", server.IFixPolicyFail, bard.NewLogger(io.Discard))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ifixes).To(Equal([]string{applies}))
+	})
+
+	it("fails for iFixes for another product", func() {
+		other := writeIFix("240006-wlp-archive-IFPH12345.jar", server.WebSphereLibertyProductId, "24.0.0.6")
+
+		_, err := server.SelectIFixes([]string{other}, server.OpenLibertyProductId, "24.0.0.6", server.IFixPolicyFail, bard.NewLogger(io.Discard))
+		Expect(err).To(MatchError(ContainSubstring("applies to com.ibm.websphere.appserver 24.0.0.6, not io.openliberty 24.0.0.6")))
+	})
+
+	it("fails for iFixes without readable metadata", func() {
+		unknown := filepath.Join(ifixesDir, "unknown.jar")
+		Expect(os.WriteFile(unknown, []byte{}, 0644)).To(Succeed())
+
+		_, err := server.SelectIFixes([]string{unknown}, server.OpenLibertyProductId, "24.0.0.6", server.IFixPolicyFail, bard.NewLogger(io.Discard))
+		Expect(err).To(MatchError(ContainSubstring("unable to determine which Liberty versions iFix " + unknown + " applies to")))
+	})
+}
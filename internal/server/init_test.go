@@ -25,6 +25,14 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("server", spec.Report(report.Terminal{}))
+	suite("FeatureUtility", testFeatureUtility)
+	suite("IFix", testIFix)
+	suite("Java", testJava)
+	suite("Messages", testMessages)
+	suite("Platform", testPlatform)
+	suite("Profile", testProfile)
+	suite("Runtime", testRuntime)
 	suite("Server", testServer)
+	suite("Verify", testVerify)
 	suite.Run(t)
 }
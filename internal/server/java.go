@@ -0,0 +1,116 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// libertyJavaSupport lists the first Liberty version to support each Java version. Java versions in between must at
+// least meet the entry of the previous version, but are not known to be supported.
+var libertyJavaSupport = []struct {
+	Java    int
+	Liberty string
+}{
+	{Java: 8, Liberty: "1.0.0.0"},
+	{Java: 11, Liberty: "19.0.0.1"},
+	{Java: 17, Liberty: "21.0.0.10"},
+	{Java: 21, Liberty: "23.0.0.10"},
+}
+
+// featureJavaVersions lists the lowest Java version for the features that require more than Java 8
+var featureJavaVersions = map[string]int{
+	"jakartaee-10.0":   11,
+	"webprofile-10.0":  11,
+	"coreprofile-10.0": 11,
+	"servlet-6.0":      11,
+	"pages-3.1":        11,
+	"cdi-4.0":          11,
+	"restfulws-3.1":    11,
+	"persistence-3.1":  11,
+	"faces-4.0":        11,
+	"microprofile-6.0": 11,
+	"microprofile-6.1": 11,
+	"microprofile-7.0": 11,
+	"microprofile-7.1": 11,
+
+	"jakartaee-11.0":   17,
+	"webprofile-11.0":  17,
+	"coreprofile-11.0": 17,
+	"servlet-6.1":      17,
+	"pages-4.0":        17,
+	"cdi-4.1":          17,
+	"restfulws-4.0":    17,
+	"persistence-3.2":  17,
+	"faces-4.1":        17,
+	"data-1.0":         17,
+	"springboot-3.0":   17,
+}
+
+// CheckJavaCompatibility returns an error if the Liberty runtime version or any of the features do not support the Java
+// version. The Liberty version is not checked if it is empty. A warning is logged if the Java version is not listed, as
+// it cannot be confirmed that Liberty supports it.
+func CheckJavaCompatibility(javaVersion int, libertyVersion string, features []string, logger bard.Logger) error {
+	for _, feature := range features {
+		if required, ok := featureJavaVersions[strings.ToLower(feature)]; ok && javaVersion < required {
+			return fmt.Errorf("feature %s requires Java %d or later, but the JVM is Java %d", feature, required, javaVersion)
+		}
+	}
+
+	if libertyVersion == "" {
+		return nil
+	}
+	libertyVersion = NormalizeLibertyVersion(libertyVersion)
+	var required string
+	known := false
+	for _, support := range libertyJavaSupport {
+		if javaVersion >= support.Java {
+			required = support.Liberty
+			known = javaVersion == support.Java
+		}
+	}
+	if required != "" && compareVersions(libertyVersion, required) < 0 {
+		return fmt.Errorf("Liberty %s does not support Java %d; Java %d requires Liberty %s or later",
+			libertyVersion, javaVersion, javaVersion, required)
+	}
+	if !known {
+		logger.Infof(color.YellowString("Warning: Unable to confirm that Liberty %s supports Java %d; the buildpack only knows the Liberty versions supporting Java %s",
+			libertyVersion, javaVersion, knownJavaVersions()))
+	}
+	return nil
+}
+
+func knownJavaVersions() string {
+	var versions []string
+	for _, support := range libertyJavaSupport {
+		versions = append(versions, fmt.Sprint(support.Java))
+	}
+	return strings.Join(versions, ", ")
+}
+
+// NormalizeLibertyVersion returns the four part Liberty version, such as `24.0.0.6`, for a version given in the three
+// part form used by the buildpack, such as `24.0.6`.
+func NormalizeLibertyVersion(version string) string {
+	if parts := strings.Split(version, "."); len(parts) == 3 {
+		return strings.Join([]string{parts[0], parts[1], "0", parts[2]}, ".")
+	}
+	return version
+}
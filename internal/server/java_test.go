@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testJava(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+		output *bytes.Buffer
+		logger bard.Logger
+	)
+
+	it.Before(func() {
+		output = &bytes.Buffer{}
+		logger = bard.NewLogger(output)
+	})
+
+	it("normalizes Liberty versions", func() {
+		Expect(server.NormalizeLibertyVersion("24.0.6")).To(Equal("24.0.0.6"))
+		Expect(server.NormalizeLibertyVersion("24.0.0.12")).To(Equal("24.0.0.12"))
+	})
+
+	it("checks the Java version required by features", func() {
+		Expect(server.CheckJavaCompatibility(11, "", []string{"servlet-6.0", "jsonp-2.1"}, logger)).To(Succeed())
+		Expect(server.CheckJavaCompatibility(11, "", []string{"jakartaEE-11.0"}, logger)).To(MatchError("feature jakartaEE-11.0 requires Java 17 or later, but the JVM is Java 11"))
+		Expect(server.CheckJavaCompatibility(8, "", []string{"microProfile-6.1"}, logger)).To(MatchError(ContainSubstring("requires Java 11")))
+	})
+
+	it("checks the Java versions supported by the Liberty version", func() {
+		Expect(server.CheckJavaCompatibility(21, "23.0.10", nil, logger)).To(Succeed())
+		Expect(server.CheckJavaCompatibility(21, "23.0.0.9", nil, logger)).To(MatchError("Liberty 23.0.0.9 does not support Java 21; Java 21 requires Liberty 23.0.0.10 or later"))
+		Expect(server.CheckJavaCompatibility(8, "19.0.0.1", nil, logger)).To(Succeed())
+		Expect(output.String()).To(BeEmpty())
+	})
+
+	it("warns about Java versions that are not listed", func() {
+		Expect(server.CheckJavaCompatibility(22, "24.0.0.1", nil, logger)).To(Succeed())
+		Expect(output.String()).To(ContainSubstring("Unable to confirm that Liberty 24.0.0.1 supports Java 22"))
+
+		output.Reset()
+		Expect(server.CheckJavaCompatibility(25, "25.0.0.1", []string{"jakartaee-11.0"}, logger)).To(Succeed())
+		Expect(output.String()).To(ContainSubstring("Unable to confirm that Liberty 25.0.0.1 supports Java 25; the buildpack only knows the Liberty versions supporting Java 8, 11, 17, 21"))
+	})
+
+	it("still requires the Liberty version of the previous Java version", func() {
+		Expect(server.CheckJavaCompatibility(22, "23.0.0.9", nil, logger)).To(MatchError("Liberty 23.0.0.9 does not support Java 22; Java 22 requires Liberty 23.0.0.10 or later"))
+	})
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// ServerReadyMessage is logged once the server has started its applications and is ready to run them
+	ServerReadyMessage = "CWWKF0011I"
+)
+
+// deploymentErrors are the messages logged when an application could not be deployed or did not start
+var deploymentErrors = []string{
+	"CWWKZ0002E", // an exception occurred while starting the application
+	"CWWKZ0004E", // an exception occurred while starting the application, typically while reading its archive
+	"CWWKZ0013E", // two applications have the same name
+	"CWWKZ0014W", // the application could not be found
+	"CWWKZ0022W", // the application did not start in time
+}
+
+var messageIdPattern = regexp.MustCompile(`\b([A-Z]{4,5}\d{4}[IWE]):`)
+
+// Message is a message logged by the server, such as `CWWKZ0001I: Application app started in 1.2 seconds.`
+type Message struct {
+	Id   string
+	Text string
+}
+
+// IsError returns true for error messages and for the warnings logged when an application did not start.
+func (m Message) IsError() bool {
+	return strings.HasSuffix(m.Id, "E") || m.IsDeploymentError()
+}
+
+// IsDeploymentError returns true for the messages logged when an application could not be deployed or did not start.
+func (m Message) IsDeploymentError() bool {
+	return slices.Contains(deploymentErrors, m.Id)
+}
+
+// ReadMessages reads the messages in `messages.log`, which is either in JSON or in the basic format. Returns no messages
+// if the log does not exist yet.
+func ReadMessages(logPath string) ([]Message, error) {
+	file, err := os.Open(logPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open %s\n%w", logPath, err)
+	}
+	defer file.Close()
+
+	var messages []Message
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		var event struct {
+			Message string `json:"message"`
+		}
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &event) == nil {
+			line = event.Message
+		}
+
+		if match := messageIdPattern.FindStringSubmatchIndex(line); match != nil {
+			messages = append(messages, Message{
+				Id:   line[match[2]:match[3]],
+				Text: strings.TrimSpace(line[match[2]:]),
+			})
+		}
+	}
+	return messages, scanner.Err()
+}
+
+// WaitForServerReady waits until the server logs that it is ready in `messages.log` and that each of the given
+// applications started. Returns an error with the offending messages if an application could not be deployed, or if the
+// server and applications are not ready within the timeout. Other errors are left to the caller, as they may not stop
+// the applications from running, such as a datasource that cannot reach its database during the build.
+func WaitForServerReady(logPath string, apps []string, timeout time.Duration, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		messages, err := ReadMessages(logPath)
+		if err != nil {
+			return err
+		}
+
+		var errorMessages []string
+		ready := false
+		for _, message := range messages {
+			if message.IsDeploymentError() {
+				errorMessages = append(errorMessages, message.Text)
+			}
+			if message.Id == ServerReadyMessage {
+				ready = true
+			}
+		}
+		if len(errorMessages) > 0 {
+			return fmt.Errorf("server was unable to start the applications:\n%s", strings.Join(errorMessages, "\n"))
+		}
+
+		started := GetStartedApplications(messages)
+		var pending []string
+		for _, app := range apps {
+			if !slices.Contains(started, app) {
+				pending = append(pending, app)
+			}
+		}
+		if ready && len(pending) == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			if ready {
+				return fmt.Errorf("applications %s did not start after %s; the last messages were:\n%s",
+					strings.Join(pending, ", "), timeout, lastMessages(messages, 5))
+			}
+			return fmt.Errorf("server was not ready after %s; the last messages were:\n%s", timeout, lastMessages(messages, 5))
+		}
+		time.Sleep(interval)
+	}
+}
+
+func lastMessages(messages []Message, count int) string {
+	if len(messages) > count {
+		messages = messages[len(messages)-count:]
+	}
+	var texts []string
+	for _, message := range messages {
+		texts = append(texts, message.Text)
+	}
+	if len(texts) == 0 {
+		return "(none)"
+	}
+	return strings.Join(texts, "\n")
+}
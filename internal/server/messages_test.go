@@ -0,0 +1,94 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testMessages(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect  = NewWithT(t).Expect
+		logPath string
+	)
+
+	it.Before(func() {
+		logPath = filepath.Join(t.TempDir(), "messages.log")
+	})
+
+	it("reads messages in the JSON and basic formats", func() {
+		Expect(os.WriteFile(logPath, []byte(`{"type":"liberty_message","ibm_messageId":"CWWKZ0001I","message":"CWWKZ0001I: Application app started in 1.234 seconds."}
+[10/18/26, 10:00:00:000 UTC] 00000024 com.ibm.ws.kernel.feature.internal.FeatureManager            A CWWKF0011I: The defaultServer server is ready to run a smarter planet.
+not a message
+`), 0644)).To(Succeed())
+
+		messages, err := server.ReadMessages(logPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messages).To(Equal([]server.Message{
+			{Id: "CWWKZ0001I", Text: "CWWKZ0001I: Application app started in 1.234 seconds."},
+			{Id: "CWWKF0011I", Text: "CWWKF0011I: The defaultServer server is ready to run a smarter planet."},
+		}))
+	})
+
+	it("waits for the server to be ready", func() {
+		Expect(os.WriteFile(logPath, []byte("CWWKF0011I: The defaultServer server is ready to run a smarter planet.\n"), 0644)).To(Succeed())
+		Expect(server.WaitForServerReady(logPath, nil, time.Second, 10*time.Millisecond)).To(Succeed())
+	})
+
+	it("waits for the applications to start", func() {
+		Expect(os.WriteFile(logPath, []byte(`CWWKZ0001I: Application app started in 1.234 seconds.
+CWWKZ0003I: The application admin updated in 0.5 seconds.
+CWWKF0011I: The defaultServer server is ready to run a smarter planet.
+`), 0644)).To(Succeed())
+		Expect(server.WaitForServerReady(logPath, []string{"admin", "app"}, time.Second, 10*time.Millisecond)).To(Succeed())
+	})
+
+	it("fails if an application does not start in time", func() {
+		Expect(os.WriteFile(logPath, []byte(`CWWKZ0001I: Application app started in 1.234 seconds.
+CWWKF0011I: The defaultServer server is ready to run a smarter planet.
+`), 0644)).To(Succeed())
+		Expect(server.WaitForServerReady(logPath, []string{"admin", "app"}, 50*time.Millisecond, 10*time.Millisecond)).To(MatchError(ContainSubstring("applications admin did not start")))
+	})
+
+	it("fails with the deployment errors logged by the server", func() {
+		Expect(os.WriteFile(logPath, []byte(`CWWKZ0022W: Application app has not started in 30.001 seconds.
+CWWKF0011I: The defaultServer server is ready to run a smarter planet.
+`), 0644)).To(Succeed())
+		Expect(server.WaitForServerReady(logPath, nil, time.Second, 10*time.Millisecond)).To(MatchError(ContainSubstring("CWWKZ0022W: Application app has not started")))
+	})
+
+	it("ignores errors that do not stop the applications", func() {
+		Expect(os.WriteFile(logPath, []byte(`CWWKZ0001I: Application app started in 1.234 seconds.
+DSRA8040E: Failed to connect to the DataSource jdbc/orders.
+CWWKF0011I: The defaultServer server is ready to run a smarter planet.
+`), 0644)).To(Succeed())
+		Expect(server.WaitForServerReady(logPath, []string{"app"}, time.Second, 10*time.Millisecond)).To(Succeed())
+	})
+
+	it("fails if the server is not ready in time", func() {
+		Expect(os.WriteFile(logPath, []byte("CWWKE0001I: The server defaultServer has been launched.\n"), 0644)).To(Succeed())
+		Expect(server.WaitForServerReady(logPath, nil, 50*time.Millisecond, 10*time.Millisecond)).To(MatchError(ContainSubstring("CWWKE0001I: The server defaultServer has been launched.")))
+	})
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"slices"
+
+	"github.com/paketo-buildpacks/liberty/internal/util"
+)
+
+// PlatformFeatures are the Liberty features that implement a Jakarta/Java EE platform version, along with the
+// compatible MicroProfile features.
+type PlatformFeatures struct {
+	Platform    string
+	WebProfile  string
+	Pages       string
+	CDI         string
+	Persistence string
+	RESTfulWS   string
+	WebSocket   string
+
+	MPConfig     string
+	MPRestClient string
+	MPHealth     string
+	MPOpenAPI    string
+}
+
+// platforms maps the Jakarta/Java EE version returned by `util.ApplicationScan.PlatformVersion` to its features
+var platforms = map[string]PlatformFeatures{
+	"7": {
+		Platform: "javaee-7.0", WebProfile: "webProfile-7.0", Pages: "jsp-2.3", CDI: "cdi-1.2", Persistence: "jpa-2.1",
+		RESTfulWS: "jaxrs-2.0", WebSocket: "websocket-1.1",
+		MPConfig: "mpConfig-1.4", MPRestClient: "mpRestClient-1.4", MPHealth: "mpHealth-2.2", MPOpenAPI: "mpOpenAPI-1.1",
+	},
+	"8": {
+		Platform: "javaee-8.0", WebProfile: "webProfile-8.0", Pages: "jsp-2.3", CDI: "cdi-2.0", Persistence: "jpa-2.2",
+		RESTfulWS: "jaxrs-2.1", WebSocket: "websocket-1.1",
+		MPConfig: "mpConfig-2.0", MPRestClient: "mpRestClient-2.0", MPHealth: "mpHealth-3.1", MPOpenAPI: "mpOpenAPI-2.0",
+	},
+	"9.1": {
+		Platform: "jakartaee-9.1", WebProfile: "webProfile-9.1", Pages: "pages-3.0", CDI: "cdi-3.0", Persistence: "persistence-3.0",
+		RESTfulWS: "restfulWS-3.0", WebSocket: "websocket-2.0",
+		MPConfig: "mpConfig-3.0", MPRestClient: "mpRestClient-3.0", MPHealth: "mpHealth-4.0", MPOpenAPI: "mpOpenAPI-3.0",
+	},
+	"10": {
+		Platform: "jakartaee-10.0", WebProfile: "webProfile-10.0", Pages: "pages-3.1", CDI: "cdi-4.0", Persistence: "persistence-3.1",
+		RESTfulWS: "restfulWS-3.1", WebSocket: "websocket-2.1",
+		MPConfig: "mpConfig-3.1", MPRestClient: "mpRestClient-3.0", MPHealth: "mpHealth-4.0", MPOpenAPI: "mpOpenAPI-3.1",
+	},
+	"11": {
+		Platform: "jakartaee-11.0", WebProfile: "webProfile-11.0", Pages: "pages-4.0", CDI: "cdi-4.1", Persistence: "persistence-3.2",
+		RESTfulWS: "restfulWS-4.0", WebSocket: "websocket-2.2",
+		MPConfig: "mpConfig-3.1", MPRestClient: "mpRestClient-4.0", MPHealth: "mpHealth-4.0", MPOpenAPI: "mpOpenAPI-4.0",
+	},
+}
+
+// featureRule enables a feature if the application refers to any of the types. Jakarta/Java EE types are given without
+// their `jakarta.` or `javax.` prefix.
+type featureRule struct {
+	feature      func(PlatformFeatures) string
+	types        []string
+	prefixes     []string
+	microProfile bool
+}
+
+var featureRules = []featureRule{
+	{
+		feature: func(p PlatformFeatures) string { return p.RESTfulWS },
+		types:   []string{"ws.rs.Path", "ws.rs.ApplicationPath"},
+	},
+	{
+		feature: func(p PlatformFeatures) string { return p.CDI },
+		types: []string{
+			"enterprise.context.ApplicationScoped",
+			"enterprise.context.RequestScoped",
+			"enterprise.context.SessionScoped",
+			"enterprise.context.Dependent",
+			"inject.Inject",
+		},
+	},
+	{
+		feature: func(p PlatformFeatures) string { return p.Persistence },
+		types:   []string{"persistence.Entity", "persistence.PersistenceContext"},
+	},
+	{
+		feature: func(p PlatformFeatures) string { return p.WebSocket },
+		types:   []string{"websocket.server.ServerEndpoint", "websocket.ClientEndpoint"},
+	},
+	{
+		feature:      func(p PlatformFeatures) string { return p.MPConfig },
+		prefixes:     []string{"org.eclipse.microprofile.config."},
+		microProfile: true,
+	},
+	{
+		feature:      func(p PlatformFeatures) string { return p.MPRestClient },
+		types:        []string{"org.eclipse.microprofile.rest.client.inject.RegisterRestClient"},
+		microProfile: true,
+	},
+	{
+		feature: func(p PlatformFeatures) string { return p.MPHealth },
+		types: []string{
+			"org.eclipse.microprofile.health.Liveness",
+			"org.eclipse.microprofile.health.Readiness",
+			"org.eclipse.microprofile.health.Startup",
+			"org.eclipse.microprofile.health.HealthCheck",
+		},
+		microProfile: true,
+	},
+	{
+		feature:      func(p PlatformFeatures) string { return p.MPOpenAPI },
+		prefixes:     []string{"org.eclipse.microprofile.openapi.annotations."},
+		microProfile: true,
+	},
+}
+
+func (r featureRule) matches(scan util.ApplicationScan) bool {
+	for _, t := range r.types {
+		if r.microProfile {
+			if scan.HasReference(t) {
+				return true
+			}
+		} else if scan.HasReference("jakarta."+t) || scan.HasReference("javax."+t) {
+			return true
+		}
+	}
+	for _, prefix := range r.prefixes {
+		if scan.HasReferencePrefix(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPlatformFeatures returns the features for the Jakarta/Java EE version, e.g. `10`.
+func GetPlatformFeatures(version string) (PlatformFeatures, bool) {
+	features, ok := platforms[version]
+	return features, ok
+}
+
+// InferDefaultFeatures returns the features that match the Jakarta/Java EE version of the scanned application.
+// Enterprise applications get the full platform, web applications using both CDI and JPA get the web profile and all
+// other web applications get Pages (JSP) plus the individual features their descriptors require. Returns nil if the
+// platform version could not be determined.
+func InferDefaultFeatures(scan util.ApplicationScan) []string {
+	platform, ok := GetPlatformFeatures(scan.PlatformVersion())
+	if !ok {
+		return nil
+	}
+
+	var features []string
+	switch {
+	case scan.IsEnterpriseApplication():
+		features = append(features, platform.Platform)
+	case scan.HasBeansXML && scan.HasPersistenceXML:
+		features = append(features, platform.WebProfile)
+	default:
+		features = append(features, platform.Pages)
+		if scan.HasBeansXML {
+			features = append(features, platform.CDI)
+		}
+		if scan.HasPersistenceXML {
+			features = append(features, platform.Persistence)
+		}
+	}
+
+	if scan.HasMicroProfileConfig {
+		features = append(features, platform.MPConfig)
+	}
+
+	return features
+}
+
+// DiscoverFeatures returns the features required by the scanned application. It starts with the default features for
+// the application's Jakarta/Java EE version and adds the features for the RESTful Web Services resources, CDI beans, JPA
+// entities, WebSocket endpoints and MicroProfile APIs that the application classes use. Jakarta/Java EE features are not
+// added if the platform or web profile feature already provides them. Returns nil if the platform version could not be
+// determined.
+func DiscoverFeatures(scan util.ApplicationScan) []string {
+	features := InferDefaultFeatures(scan)
+	if features == nil {
+		return nil
+	}
+
+	platform, _ := GetPlatformFeatures(scan.PlatformVersion())
+	includesEE := features[0] == platform.Platform || features[0] == platform.WebProfile
+
+	for _, rule := range featureRules {
+		if !rule.microProfile && includesEE {
+			continue
+		}
+		if !rule.matches(scan) {
+			continue
+		}
+		if feature := rule.feature(platform); !slices.Contains(features, feature) {
+			features = append(features, feature)
+		}
+	}
+
+	return features
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testPlatform(t *testing.T, when spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	when("inferring default features", func() {
+		it("returns nil if the platform version is unknown", func() {
+			Expect(server.InferDefaultFeatures(util.ApplicationScan{})).To(BeNil())
+		})
+
+		it("returns pages for a Jakarta EE 10 web application", func() {
+			scan := util.ApplicationScan{WebXMLVersion: "6.0"}
+			Expect(server.InferDefaultFeatures(scan)).To(Equal([]string{"pages-3.1"}))
+		})
+
+		it("returns jsp for a javax web application", func() {
+			scan := util.ApplicationScan{JavaxReferences: true}
+			Expect(server.InferDefaultFeatures(scan)).To(Equal([]string{"jsp-2.3"}))
+		})
+
+		it("adds individual features required by descriptors", func() {
+			scan := util.ApplicationScan{JakartaReferences: true, HasBeansXML: true, HasMicroProfileConfig: true}
+			Expect(server.InferDefaultFeatures(scan)).To(Equal([]string{"pages-3.1", "cdi-4.0", "mpConfig-3.1"}))
+		})
+
+		it("returns the web profile if CDI and persistence are both required", func() {
+			scan := util.ApplicationScan{WebXMLVersion: "5.0", HasBeansXML: true, HasPersistenceXML: true}
+			Expect(server.InferDefaultFeatures(scan)).To(Equal([]string{"webProfile-9.1"}))
+		})
+
+		it("returns the full platform for enterprise applications", func() {
+			scan := util.ApplicationScan{ApplicationXMLVersion: "11", ApplicationXMLNamespace: util.JakartaEENamespace}
+			Expect(server.InferDefaultFeatures(scan)).To(Equal([]string{"jakartaee-11.0"}))
+		})
+	})
+	when("discovering features", func() {
+		it("returns nil if the platform version is unknown", func() {
+			scan := util.ApplicationScan{References: map[string]bool{"org.eclipse.microprofile.health.Readiness": true}}
+			Expect(server.DiscoverFeatures(scan)).To(BeNil())
+		})
+
+		it("adds features for the Jakarta EE and MicroProfile APIs used", func() {
+			scan := util.ApplicationScan{
+				JakartaReferences: true,
+				References: map[string]bool{
+					"jakarta.ws.rs.Path":                                             true,
+					"jakarta.enterprise.context.ApplicationScoped":                   true,
+					"jakarta.persistence.Entity":                                     true,
+					"jakarta.websocket.server.ServerEndpoint":                        true,
+					"org.eclipse.microprofile.rest.client.inject.RegisterRestClient": true,
+					"org.eclipse.microprofile.health.Readiness":                      true,
+					"org.eclipse.microprofile.openapi.annotations.Operation":         true,
+					"org.eclipse.microprofile.config.inject.ConfigProperty":          true,
+				},
+			}
+			Expect(server.DiscoverFeatures(scan)).To(Equal([]string{
+				"pages-3.1",
+				"restfulWS-3.1",
+				"cdi-4.0",
+				"persistence-3.1",
+				"websocket-2.1",
+				"mpConfig-3.1",
+				"mpRestClient-3.0",
+				"mpHealth-4.0",
+				"mpOpenAPI-3.1",
+			}))
+		})
+
+		it("uses javax features for Java EE applications", func() {
+			scan := util.ApplicationScan{
+				JavaxReferences: true,
+				References:      map[string]bool{"javax.ws.rs.ApplicationPath": true},
+			}
+			Expect(server.DiscoverFeatures(scan)).To(Equal([]string{"jsp-2.3", "jaxrs-2.1"}))
+		})
+
+		it("does not duplicate features required by descriptors", func() {
+			scan := util.ApplicationScan{
+				JakartaReferences: true,
+				HasBeansXML:       true,
+				References:        map[string]bool{"jakarta.inject.Inject": true},
+			}
+			Expect(server.DiscoverFeatures(scan)).To(Equal([]string{"pages-3.1", "cdi-4.0"}))
+		})
+
+		it("only adds MicroProfile features to the web profile", func() {
+			scan := util.ApplicationScan{
+				WebXMLVersion:     "6.1",
+				HasBeansXML:       true,
+				HasPersistenceXML: true,
+				References: map[string]bool{
+					"jakarta.ws.rs.Path":                       true,
+					"org.eclipse.microprofile.health.Liveness": true,
+				},
+			}
+			Expect(server.DiscoverFeatures(scan)).To(Equal([]string{"webProfile-11.0", "mpHealth-4.0"}))
+		})
+	})
+}
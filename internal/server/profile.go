@@ -0,0 +1,180 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Profile describes a Liberty runtime profile packaged by the buildpack.
+type Profile struct {
+	// Name is the profile name, e.g. `webProfile11`
+	Name string
+
+	// DefaultFeatures are the features enabled when the server configuration does not list any features
+	DefaultFeatures []string
+
+	// Features are the features packaged with the profile runtime. Empty for profiles that do not enumerate their
+	// features, such as `kernel` and `full`.
+	Features []string
+}
+
+// ContainsFeatures returns true if every one of the given features is packaged with the profile runtime. Feature names
+// are compared case-insensitively, as they are by Liberty.
+func (p Profile) ContainsFeatures(features []string) bool {
+	if len(p.Features) == 0 {
+		return false
+	}
+
+	packaged := make(map[string]bool, len(p.Features))
+	for _, feature := range p.Features {
+		packaged[strings.ToLower(feature)] = true
+	}
+
+	for _, feature := range features {
+		if !packaged[strings.ToLower(feature)] {
+			return false
+		}
+	}
+	return true
+}
+
+// ProfileCatalog is the list of profiles available for a Liberty distribution type.
+type ProfileCatalog struct {
+	profiles []Profile
+}
+
+// NewProfileCatalog creates the profile catalog for the distribution type (e.g. `open-liberty-runtime`) from the
+// dependencies in the buildpack metadata. The profile name is the part of the dependency ID after the distribution
+// type. Each dependency may declare `default-features` and the `features` packaged with it.
+func NewProfileCatalog(metadata map[string]interface{}, distType string) (ProfileCatalog, error) {
+	catalog := ProfileCatalog{}
+	if distType == "" {
+		return catalog, nil
+	}
+
+	dependencies, ok := metadata["dependencies"].([]map[string]interface{})
+	if !ok {
+		return catalog, nil
+	}
+
+	prefix := distType + "-"
+	for _, dependency := range dependencies {
+		id, _ := dependency["id"].(string)
+		if !strings.HasPrefix(id, prefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(id, prefix)
+		if _, found := catalog.Get(name); found {
+			continue
+		}
+
+		defaultFeatures, err := toStringSlice(dependency["default-features"])
+		if err != nil {
+			return ProfileCatalog{}, fmt.Errorf("unable to read default features for dependency '%s'\n%w", id, err)
+		}
+		features, err := toStringSlice(dependency["features"])
+		if err != nil {
+			return ProfileCatalog{}, fmt.Errorf("unable to read features for dependency '%s'\n%w", id, err)
+		}
+
+		catalog.profiles = append(catalog.profiles, Profile{
+			Name:            name,
+			DefaultFeatures: defaultFeatures,
+			Features:        features,
+		})
+	}
+
+	return catalog, nil
+}
+
+// Names returns the names of all profiles in the catalog.
+func (c ProfileCatalog) Names() []string {
+	names := make([]string, 0, len(c.profiles))
+	for _, profile := range c.profiles {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// Get returns the profile with the given name.
+func (c ProfileCatalog) Get(name string) (Profile, bool) {
+	for _, profile := range c.profiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// IsValid returns true if the profile is in the catalog.
+func (c ProfileCatalog) IsValid(name string) bool {
+	_, found := c.Get(name)
+	return found
+}
+
+// DefaultFeatures returns the default features of the profile, if any.
+func (c ProfileCatalog) DefaultFeatures(name string) []string {
+	profile, _ := c.Get(name)
+	return profile.DefaultFeatures
+}
+
+// FindSmallestProfile returns the profile from the candidates that packages the fewest features while still
+// containing all the given features. Candidates earlier in the list win ties. Returns false if no features are given
+// or no candidate contains all of them.
+func FindSmallestProfile(candidates []Profile, features []string) (string, bool) {
+	if len(features) == 0 {
+		return "", false
+	}
+
+	var selected *Profile
+	for i, candidate := range candidates {
+		if !candidate.ContainsFeatures(features) {
+			continue
+		}
+		if selected == nil || len(candidate.Features) < len(selected.Features) {
+			selected = &candidates[i]
+		}
+	}
+
+	if selected == nil {
+		return "", false
+	}
+	return selected.Name, true
+}
+
+func toStringSlice(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string but found %T", item)
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("expected list of strings but found %T", value)
+}
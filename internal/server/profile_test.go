@@ -0,0 +1,144 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testProfile(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect   = NewWithT(t).Expect
+		metadata = map[string]interface{}{
+			"dependencies": []map[string]interface{}{
+				{"id": "open-liberty-runtime-kernel", "default-features": []interface{}{"jsp-2.3"}},
+				{"id": "open-liberty-runtime-webProfile11", "default-features": []interface{}{"webProfile-11.0"},
+					"features": []interface{}{"webProfile-11.0", "servlet-6.1", "cdi-4.1", "restfulWS-4.0"}},
+				{"id": "open-liberty-runtime-jakartaee11", "default-features": []interface{}{"jakartaee-11.0"},
+					"features": []interface{}{"jakartaee-11.0", "webProfile-11.0", "servlet-6.1", "cdi-4.1", "restfulWS-4.0", "batch-2.1"}},
+				{"id": "open-liberty-runtime-microProfile7", "default-features": []interface{}{"microProfile-7.0"},
+					"features": []interface{}{"microProfile-7.0", "mpHealth-4.0", "servlet-6.0", "cdi-4.0"}},
+				{"id": "websphere-liberty-runtime-javaee7", "default-features": []interface{}{"javaee-7.0"}},
+			},
+		}
+	)
+
+	when("reading the catalog", func() {
+		it("derives the profiles for the distribution type", func() {
+			catalog, err := server.NewProfileCatalog(metadata, "open-liberty-runtime")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog.Names()).To(Equal([]string{"kernel", "webProfile11", "jakartaee11", "microProfile7"}))
+			Expect(catalog.IsValid("webProfile11")).To(BeTrue())
+			Expect(catalog.IsValid("javaee7")).To(BeFalse())
+		})
+
+		it("reads the default features", func() {
+			catalog, err := server.NewProfileCatalog(metadata, "websphere-liberty-runtime")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog.Names()).To(Equal([]string{"javaee7"}))
+			Expect(catalog.DefaultFeatures("javaee7")).To(Equal([]string{"javaee-7.0"}))
+			Expect(catalog.DefaultFeatures("kernel")).To(BeEmpty())
+		})
+
+		it("is empty without a distribution type", func() {
+			catalog, err := server.NewProfileCatalog(metadata, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog.Names()).To(BeEmpty())
+		})
+
+		it("fails when features are not a list of strings", func() {
+			_, err := server.NewProfileCatalog(map[string]interface{}{
+				"dependencies": []map[string]interface{}{
+					{"id": "open-liberty-runtime-kernel", "features": "servlet-6.1"},
+				},
+			}, "open-liberty-runtime")
+			Expect(err).To(MatchError(ContainSubstring("unable to read features for dependency 'open-liberty-runtime-kernel'")))
+		})
+	})
+
+	when("checking profile contents", func() {
+		var catalog server.ProfileCatalog
+
+		it.Before(func() {
+			var err error
+			catalog, err = server.NewProfileCatalog(metadata, "open-liberty-runtime")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("contains packaged features regardless of case", func() {
+			profile, _ := catalog.Get("webProfile11")
+			Expect(profile.ContainsFeatures([]string{"servlet-6.1", "CDI-4.1"})).To(BeTrue())
+		})
+
+		it("does not contain features from other profiles", func() {
+			profile, _ := catalog.Get("webProfile11")
+			Expect(profile.ContainsFeatures([]string{"servlet-6.1", "batch-2.1"})).To(BeFalse())
+		})
+
+		it("does not contain any features for the kernel", func() {
+			profile, _ := catalog.Get("kernel")
+			Expect(profile.ContainsFeatures([]string{"servlet-6.1"})).To(BeFalse())
+		})
+	})
+
+	when("finding the smallest profile", func() {
+		var candidates []server.Profile
+
+		it.Before(func() {
+			catalog, err := server.NewProfileCatalog(metadata, "open-liberty-runtime")
+			Expect(err).NotTo(HaveOccurred())
+			for _, name := range catalog.Names() {
+				profile, _ := catalog.Get(name)
+				candidates = append(candidates, profile)
+			}
+		})
+
+		it("selects the web profile when it contains all features", func() {
+			profile, ok := server.FindSmallestProfile(candidates, []string{"servlet-6.1", "restfulWS-4.0"})
+			Expect(ok).To(BeTrue())
+			Expect(profile).To(Equal("webProfile11"))
+		})
+
+		it("selects the full platform when the web profile is not enough", func() {
+			profile, ok := server.FindSmallestProfile(candidates, []string{"servlet-6.1", "batch-2.1"})
+			Expect(ok).To(BeTrue())
+			Expect(profile).To(Equal("jakartaee11"))
+		})
+
+		it("selects the micro profile for MicroProfile features", func() {
+			profile, ok := server.FindSmallestProfile(candidates, []string{"mpHealth-4.0", "cdi-4.0"})
+			Expect(ok).To(BeTrue())
+			Expect(profile).To(Equal("microProfile7"))
+		})
+
+		it("returns false when no profile contains all features", func() {
+			profile, ok := server.FindSmallestProfile(candidates, []string{"servlet-6.1", "jsp-2.3"})
+			Expect(ok).To(BeFalse())
+			Expect(profile).To(BeEmpty())
+		})
+
+		it("returns false when no features are required", func() {
+			_, ok := server.FindSmallestProfile(candidates, []string{})
+			Expect(ok).To(BeFalse())
+		})
+	})
+}
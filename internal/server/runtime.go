@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	OpenLibertyProductId      = "io.openliberty"
+	WebSphereLibertyProductId = "com.ibm.websphere.appserver"
+)
+
+// RuntimeInfo describes a Liberty runtime installation.
+type RuntimeInfo struct {
+	// ProductId is `io.openliberty` for Open Liberty and `com.ibm.websphere.appserver` for WebSphere Liberty
+	ProductId string
+	Name      string
+	Version   string
+}
+
+// IsOpenLiberty returns true if the runtime is Open Liberty rather than WebSphere Liberty.
+func (r RuntimeInfo) IsOpenLiberty() bool {
+	return r.ProductId == OpenLibertyProductId
+}
+
+// ReadRuntimeInfo reads the product information of the Liberty runtime at the given path from the properties files in
+// `lib/versions`. WebSphere Liberty also ships the Open Liberty properties, so its own are read first.
+func ReadRuntimeInfo(runtimePath string) (RuntimeInfo, error) {
+	for _, file := range []string{"WebSphereApplicationServer.properties", "openliberty.properties"} {
+		properties, err := readProperties(filepath.Join(runtimePath, "lib", "versions", file))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return RuntimeInfo{}, fmt.Errorf("unable to read %s\n%w", file, err)
+		}
+
+		info := RuntimeInfo{
+			ProductId: properties["com.ibm.websphere.productId"],
+			Name:      properties["com.ibm.websphere.productName"],
+			Version:   properties["com.ibm.websphere.productVersion"],
+		}
+		if info.ProductId == "" || info.Version == "" {
+			return RuntimeInfo{}, fmt.Errorf("unable to find product ID and version in %s", file)
+		}
+		return info, nil
+	}
+	return RuntimeInfo{}, fmt.Errorf("unable to find product information in %s", filepath.Join(runtimePath, "lib", "versions"))
+}
+
+// readProperties reads the `key=value` pairs of a simple Java properties file
+func readProperties(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return properties, scanner.Err()
+}
+
+// GetRuntimeFeatures returns the short names, in lower case, of the public features installed in the `lib/features`
+// directory of the Liberty runtime at the given path.
+func GetRuntimeFeatures(runtimePath string) ([]string, error) {
+	manifests, err := filepath.Glob(filepath.Join(runtimePath, "lib", "features", "*.mf"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list feature manifests\n%w", err)
+	}
+
+	var features []string
+	for _, manifest := range manifests {
+		headers, err := readManifest(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read feature manifest %s\n%w", filepath.Base(manifest), err)
+		}
+		if shortName := headers["IBM-ShortName"]; shortName != "" {
+			features = append(features, strings.ToLower(shortName))
+		}
+	}
+	sort.Strings(features)
+	return features, nil
+}
+
+// MissingFeatures returns the required features that are not in the available features. Features of product extensions
+// and user features, which are prefixed with the extension name such as `usr:`, are not checked.
+func MissingFeatures(required []string, available []string) []string {
+	var missing []string
+	for _, feature := range required {
+		if strings.Contains(feature, ":") {
+			continue
+		}
+		if !slices.Contains(available, strings.ToLower(feature)) {
+			missing = append(missing, feature)
+		}
+	}
+	return missing
+}
+
+// readManifest reads the main headers of a manifest, joining continuation lines
+func readManifest(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	headers := map[string]string{}
+	var name string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, " ") && name != "" {
+			headers[name] += line[1:]
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			name = ""
+			continue
+		}
+		name = strings.TrimSpace(key)
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers, scanner.Err()
+}
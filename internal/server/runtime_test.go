@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testRuntime(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect      = NewWithT(t).Expect
+		runtimePath string
+	)
+
+	it.Before(func() {
+		var err error
+		runtimePath, err = os.MkdirTemp("", "runtime")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(runtimePath, "lib", "versions"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "lib", "versions", "openliberty.properties"), []byte(`# Open Liberty
+com.ibm.websphere.productId=io.openliberty
+com.ibm.websphere.productName=Open Liberty
+com.ibm.websphere.productVersion=24.0.0.6
+`), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(runtimePath)).To(Succeed())
+	})
+
+	it("reads the Open Liberty product information", func() {
+		info, err := server.ReadRuntimeInfo(runtimePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info).To(Equal(server.RuntimeInfo{ProductId: "io.openliberty", Name: "Open Liberty", Version: "24.0.0.6"}))
+		Expect(info.IsOpenLiberty()).To(BeTrue())
+	})
+
+	it("prefers the WebSphere Liberty product information", func() {
+		Expect(os.WriteFile(filepath.Join(runtimePath, "lib", "versions", "WebSphereApplicationServer.properties"), []byte(`com.ibm.websphere.productId=com.ibm.websphere.appserver
+com.ibm.websphere.productName=WebSphere Application Server
+com.ibm.websphere.productVersion=24.0.0.6
+com.ibm.websphere.productEdition=BASE
+`), 0644)).To(Succeed())
+
+		info, err := server.ReadRuntimeInfo(runtimePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.ProductId).To(Equal("com.ibm.websphere.appserver"))
+		Expect(info.IsOpenLiberty()).To(BeFalse())
+	})
+
+	it("fails if there is no product information", func() {
+		Expect(os.RemoveAll(filepath.Join(runtimePath, "lib"))).To(Succeed())
+
+		_, err := server.ReadRuntimeInfo(runtimePath)
+		Expect(err).To(MatchError(ContainSubstring("unable to find product information")))
+	})
+
+	it("lists the features of the runtime", func() {
+		featuresPath := filepath.Join(runtimePath, "lib", "features")
+		Expect(os.MkdirAll(featuresPath, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(featuresPath, "com.ibm.websphere.appserver.servlet-6.0.mf"), []byte(`Manifest-Version: 1.0
+IBM-ShortName: servlet-6.0
+Subsystem-SymbolicName: com.ibm.websphere.appserver.servlet-6.0; visibility
+ :=public; singleton:=true
+`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(featuresPath, "com.ibm.websphere.appserver.pages-3.1.mf"), []byte("IBM-ShortName: pages-3.1\r\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(featuresPath, "com.ibm.websphere.appserver.internal.mf"), []byte("Subsystem-SymbolicName: internal\n"), 0644)).To(Succeed())
+
+		features, err := server.GetRuntimeFeatures(runtimePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(features).To(Equal([]string{"pages-3.1", "servlet-6.0"}))
+
+		Expect(server.MissingFeatures([]string{"Servlet-6.0", "jdbc-4.3", "usr:custom-1.0"}, features)).To(Equal([]string{"jdbc-4.3"}))
+	})
+}
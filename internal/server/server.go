@@ -25,8 +25,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/antchfx/xmlquery"
 	"github.com/paketo-buildpacks/liberty/internal/util"
@@ -66,7 +68,9 @@ func GetServerConfigs(serverPath string) ([]string, error) {
 	return configs, nil
 }
 
-func GetFeatureList(profile string, serverPath string, additionalFeatures []string) ([]string, error) {
+// GetFeatureList returns the sorted list of features configured for the server along with any additional features.
+// The default features are returned if no features are configured.
+func GetFeatureList(defaultFeatures []string, serverPath string, additionalFeatures []string) ([]string, error) {
 	featureMap := make(map[string]bool, 0)
 
 	// Add any additional features
@@ -89,7 +93,7 @@ func GetFeatureList(profile string, serverPath string, additionalFeatures []stri
 	}
 
 	if len(featureMap) == 0 {
-		return GetDefaultFeatures(profile), nil
+		return defaultFeatures, nil
 	}
 
 	var features []string
@@ -101,58 +105,19 @@ func GetFeatureList(profile string, serverPath string, additionalFeatures []stri
 	return features, nil
 }
 
-func IsValidOpenLibertyProfile(profile string) bool {
-	return profile == "full" ||
-		profile == "kernel" ||
-		profile == "jakartaee11" ||
-		profile == "javaee8" ||
-		profile == "webProfile11" ||
-		profile == "webProfile8" ||
-		profile == "microProfile7" ||
-		profile == "microProfile4"
-}
-
-func IsValidWebSphereLibertyProfile(profile string) bool {
-	return profile == "kernel" ||
-		profile == "jakartaee11" ||
-		profile == "javaee8" ||
-		profile == "javaee7" ||
-		profile == "webProfile11" ||
-		profile == "webProfile8" ||
-		profile == "webProfile7"
-}
-
-func GetDefaultFeatures(serverProfile string) []string {
-	switch serverProfile {
-	case "full", "kernel":
-		return []string{"jsp-2.3"}
-	case "jakartaee9":
-		return []string{"jakartaee-10.0"}
-	case "javaee8":
-		return []string{"javaee-8.0"}
-	case "webProfile9":
-		return []string{"webProfile-10.0"}
-	case "webProfile8":
-		return []string{"webProfile-8.0"}
-	case "microProfile7":
-		return []string{"microProfile-7.0"}
-	case "microProfile4":
-		return []string{"microProfile-4.1"}
-	}
-	return []string{}
-}
-
-// SetUserDirectory sets the server's user directory to the specified directory.
-func SetUserDirectory(srcUserPath string, destUserPath string, serverName string) error {
+// SetUserDirectory sets the user directory of the given servers to the specified directory.
+func SetUserDirectory(srcUserPath string, destUserPath string, serverNames []string) error {
 	// Copy the configDropins directory to the new user directory. This is needed by Liberty runtimes provided in the
 	// stack run image
 	configDropinsDir := filepath.Join(destUserPath, "servers", "defaultServer", "configDropins")
 	if configDropinsFound, err := sherpa.DirExists(configDropinsDir); err != nil {
 		return fmt.Errorf("unable to read configDropins directory\n%w", err)
 	} else if configDropinsFound {
-		newConfigDropinsDir := filepath.Join(srcUserPath, "servers", serverName, "configDropins")
-		if err := sherpa.CopyDir(configDropinsDir, newConfigDropinsDir); err != nil {
-			return fmt.Errorf("unable to copy configDropins to new user directory\n%w", err)
+		for _, serverName := range serverNames {
+			newConfigDropinsDir := filepath.Join(srcUserPath, "servers", serverName, "configDropins")
+			if err := sherpa.CopyDir(configDropinsDir, newConfigDropinsDir); err != nil {
+				return fmt.Errorf("unable to copy configDropins to new user directory\n%w", err)
+			}
 		}
 	}
 	if err := util.DeleteAndLinkPath(srcUserPath, destUserPath); err != nil {
@@ -225,32 +190,65 @@ func InstallIFixes(installRoot string, ifixes []string, executor effect.Executor
 	return nil
 }
 
-func InstallFeatures(runtimePath string, serverName string, executor effect.Executor, logger bard.Logger) error {
+// InstallFeatures installs the features of the server with featureUtility. If a local repository is given, features
+// are resolved from it before downloading them and the downloaded features are kept there. Otherwise, nothing is cached.
+func InstallFeatures(runtimePath string, serverName string, localRepo string, executor effect.Executor, logger bard.Logger) error {
 	logger.Bodyf("Installing features...")
 
 	args := []string{
 		"installServerFeatures",
 		"--acceptLicense",
-		"--noCache",
-		serverName,
 	}
 
+	var env []string
+	if localRepo != "" {
+		env = append(os.Environ(), fmt.Sprintf("FEATURE_LOCAL_REPO=%s", localRepo))
+	} else {
+		args = append(args, "--noCache")
+	}
+	args = append(args, serverName)
+
 	if logger.IsDebugEnabled() {
 		args = append(args, "--verbose")
 	}
 
+	// The output is kept to diagnose failures. The same writer is used for stdout and stderr so that they are not
+	// written at the same time.
+	output := &bytes.Buffer{}
+	writer := io.MultiWriter(bard.NewWriter(logger.InfoWriter(), bard.WithIndent(3)), output)
 	if err := executor.Execute(effect.Execution{
 		Command: filepath.Join(runtimePath, "bin", "featureUtility"),
 		Args:    args,
-		Stdout:  bard.NewWriter(logger.InfoWriter(), bard.WithIndent(3)),
-		Stderr:  bard.NewWriter(logger.InfoWriter(), bard.WithIndent(3)),
+		Env:     env,
+		Stdout:  writer,
+		Stderr:  writer,
 	}); err != nil {
-		return fmt.Errorf("unable to install feature\n%w", err)
+		return diagnoseFeatureInstallFailure(runtimePath, serverName, output.String(), err)
 	}
 
 	return nil
 }
 
+// diagnoseFeatureInstallFailure returns the error for a failed featureUtility run, listing the cause of each error it
+// reported and how to fix it
+func diagnoseFeatureInstallFailure(runtimePath string, serverName string, output string, err error) error {
+	userDir := os.Getenv("WLP_USER_DIR")
+	if userDir == "" {
+		userDir = filepath.Join(runtimePath, "usr")
+	}
+
+	problems, diagnoseErr := DiagnoseFeatureInstall(output, filepath.Join(userDir, "servers", serverName))
+	if diagnoseErr != nil || len(problems) == 0 {
+		return fmt.Errorf("unable to install feature\n%w", err)
+	}
+
+	var summary []string
+	for _, problem := range problems {
+		summary = append(summary, problem.String())
+	}
+	return fmt.Errorf("unable to install features for server %s:\n%s\n%w", serverName, strings.Join(summary, "\n"), err)
+}
+
 type InstalledIFix struct {
 	APAR string
 	IFix string
@@ -319,6 +317,7 @@ type Config struct {
 	Applications           []ApplicationConfig `xml:"application"`
 	WebApplications        []ApplicationConfig `xml:"webApplication"`
 	EnterpriseApplications []ApplicationConfig `xml:"enterpriseApplication"`
+	SpringBootApplications []ApplicationConfig `xml:"springBootApplication"`
 	HTTPEndpoint           struct {
 		Host string `xml:"host,attr"`
 	} `xml:"httpEndpoint"`
@@ -331,9 +330,36 @@ type ApplicationConfig struct {
 	ContextRoot string `xml:"context-root,attr,omitempty"`
 	Type        string `xml:"type,attr,omitempty"`
 
+	// WebExtensions override the settings of the web modules of an enterprise application
+	WebExtensions []WebExtension `xml:"web-ext"`
+
+	Classloader Classloader `xml:"classloader"`
+
 	AppElement string `xml:"-"`
 }
 
+// AddCommonLibraryRef adds the library to the libraries shared with the application, keeping any already referenced.
+func (a *ApplicationConfig) AddCommonLibraryRef(id string) {
+	refs := strings.FieldsFunc(a.Classloader.CommonLibraryRef, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if !slices.Contains(refs, id) {
+		refs = append(refs, id)
+	}
+	a.Classloader.CommonLibraryRef = strings.Join(refs, ",")
+}
+
+// Classloader is the `classloader` configuration of an application.
+type Classloader struct {
+	CommonLibraryRef string `xml:"commonLibraryRef,attr,omitempty"`
+}
+
+// WebExtension is the `web-ext` configuration of a web module in an enterprise application.
+type WebExtension struct {
+	ModuleName  string `xml:"moduleName,attr"`
+	ContextRoot string `xml:"context-root,attr"`
+}
+
 type ApplicationConfigs struct {
 	appMap map[string][]ApplicationConfig
 }
@@ -344,6 +370,7 @@ func ProcessApplicationConfigs(config Config) ApplicationConfigs {
 		"application":           config.Applications,
 		"webApplication":        config.WebApplications,
 		"enterpriseApplication": config.EnterpriseApplications,
+		"springBootApplication": config.SpringBootApplications,
 	} {
 		for _, app := range apps {
 			app.AppElement = appType
@@ -398,11 +425,38 @@ func (apps *ApplicationConfigs) GetApplication(id string) (ApplicationConfig, er
 		if config.AppElement != "" {
 			mergedConfig.AppElement = config.AppElement
 		}
+		if config.Classloader.CommonLibraryRef != "" {
+			mergedConfig.Classloader.CommonLibraryRef = config.Classloader.CommonLibraryRef
+		}
+		mergedConfig.WebExtensions = MergeWebExtensions(mergedConfig.WebExtensions, config.WebExtensions)
 	}
 
 	return mergedConfig, nil
 }
 
+// MergeWebExtensions returns the web extensions with the overrides applied. Overrides replace the extension for the
+// same module and are otherwise added.
+func MergeWebExtensions(extensions []WebExtension, overrides []WebExtension) []WebExtension {
+	if len(overrides) == 0 {
+		return extensions
+	}
+
+	merged := append([]WebExtension{}, extensions...)
+	for _, override := range overrides {
+		found := false
+		for i := range merged {
+			if merged[i].ModuleName == override.ModuleName {
+				merged[i] = override
+				found = true
+			}
+		}
+		if !found {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
 func ReadServerConfig(configPath string) (Config, error) {
 	xmlFile, err := os.Open(configPath)
 	if err != nil {
@@ -448,7 +502,7 @@ func (n *ConfigNode) GetApplicationNode() (*xmlquery.Node, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to find server configuration\n%w", err)
 	}
-	for _, appElement := range []string{"application", "webApplication", "enterpriseApplication"} {
+	for _, appElement := range []string{"application", "webApplication", "enterpriseApplication", "springBootApplication"} {
 		appConfig, err := xmlquery.Query(serverNode, "//"+appElement)
 		if err != nil {
 			return nil, fmt.Errorf("unable to find app configuration '%s'\n%w", appElement, err)
@@ -17,6 +17,7 @@
 package server_test
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -67,7 +68,7 @@ func testServer(t *testing.T, when spec.G, it spec.S) {
 			Expect(os.MkdirAll(newServerDir, 0755)).To(Succeed())
 			Expect(os.WriteFile(filepath.Join(newServerDir, "server.xml"), []byte{}, 0644)).To(Succeed())
 
-			Expect(server.SetUserDirectory(newUserDir, wlpPath, "defaultServer")).To(Succeed())
+			Expect(server.SetUserDirectory(newUserDir, wlpPath, []string{"defaultServer"})).To(Succeed())
 			newConfigPath, err := filepath.EvalSymlinks(server.GetServerConfigPath(newServerDir))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(newConfigPath).To(Equal(filepath.Join(newServerDir, "server.xml")))
@@ -87,7 +88,7 @@ func testServer(t *testing.T, when spec.G, it spec.S) {
 			Expect(os.MkdirAll(configDropinsDir, 0755)).To(Succeed())
 			Expect(os.WriteFile(filepath.Join(configDropinsDir, "test-config.xml"), []byte{}, 0644)).To(Succeed())
 
-			Expect(server.SetUserDirectory(wlpPath, newUserDir, "defaultServer")).To(Succeed())
+			Expect(server.SetUserDirectory(wlpPath, newUserDir, []string{"defaultServer"})).To(Succeed())
 			Expect(filepath.Join(configDropinsDir, "test-config.xml")).To(BeARegularFile())
 
 			Expect(os.RemoveAll(wlpPath)).To(Succeed())
@@ -265,13 +266,55 @@ func testServer(t *testing.T, when spec.G, it spec.S) {
 		it("works", func() {
 			executor := &mocks.Executor{}
 			executor.On("Execute", mock.Anything).Return(nil)
-			Expect(server.InstallFeatures(wlpPath, "testServer", executor, bard.NewLogger(io.Discard)))
+			Expect(server.InstallFeatures(wlpPath, "testServer", "", executor, bard.NewLogger(io.Discard)))
 
 			execution := executor.Calls[0].Arguments[0].(effect.Execution)
 			Expect(execution.Command).To(Equal(filepath.Join(wlpPath, "bin", "featureUtility")))
 			Expect(execution.Args).To(Equal([]string{"installServerFeatures", "--acceptLicense", "--noCache", "testServer"}))
 		})
 
+		it("uses the local repository", func() {
+			executor := &mocks.Executor{}
+			executor.On("Execute", mock.Anything).Return(nil)
+			Expect(server.InstallFeatures(wlpPath, "testServer", "/layers/feature-cache", executor, bard.NewLogger(io.Discard))).To(Succeed())
+
+			execution := executor.Calls[0].Arguments[0].(effect.Execution)
+			Expect(execution.Args).To(Equal([]string{"installServerFeatures", "--acceptLicense", "testServer"}))
+			Expect(execution.Env).To(ContainElement("FEATURE_LOCAL_REPO=/layers/feature-cache"))
+		})
+
+		it("diagnoses failures", func() {
+			serverPath := filepath.Join(wlpPath, "usr", "servers", "testServer")
+			Expect(os.MkdirAll(serverPath, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte(`<server>
+  <featureManager>
+    <feature>servlet-2.x</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+
+			executor := &mocks.Executor{}
+			executor.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
+				_, err := args.Get(0).(effect.Execution).Stderr.Write([]byte(
+					"CWWKF1299E: Unable to obtain the following features: servlet-2.x. Ensure that the features are valid for Open Liberty.\n"))
+				Expect(err).NotTo(HaveOccurred())
+			}).Return(fmt.Errorf("exit status 21"))
+
+			err := server.InstallFeatures(wlpPath, "testServer", "", executor, bard.NewLogger(io.Discard))
+			Expect(err).To(MatchError(ContainSubstring("unable to install features for server testServer:\n" +
+				"CWWKF1299E: Unable to obtain the following features: servlet-2.x. Ensure that the features are valid for Open Liberty.\n" +
+				"  Cause: the features could not be found\n" +
+				"  Features: servlet-2.x (in server.xml)\n")))
+			Expect(err).To(MatchError(ContainSubstring("exit status 21")))
+		})
+
+		it("reports failures it cannot diagnose", func() {
+			executor := &mocks.Executor{}
+			executor.On("Execute", mock.Anything).Return(fmt.Errorf("exit status 1"))
+
+			err := server.InstallFeatures(wlpPath, "testServer", "", executor, bard.NewLogger(io.Discard))
+			Expect(err).To(MatchError("unable to install feature\nexit status 1"))
+		})
+
 		it("lists installed features", func() {
 			executor := &mocks.Executor{}
 			executor.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
@@ -354,5 +397,74 @@ func testServer(t *testing.T, when spec.G, it spec.S) {
 				AppElement:  "enterpriseApplication",
 			}))
 		})
+
+		it("reads and merges web module extensions of enterprise applications", func() {
+			configPath := filepath.Join(testPath, "server.xml")
+			Expect(os.WriteFile(configPath, []byte(`<server>
+  <enterpriseApplication id="sample-ear" location="sample.ear">
+    <web-ext moduleName="shop" context-root="/shop"/>
+    <web-ext moduleName="admin" context-root="/admin"/>
+  </enterpriseApplication>
+  <enterpriseApplication id="sample-ear">
+    <web-ext moduleName="admin" context-root="/console"/>
+  </enterpriseApplication>
+</server>`), 0644)).To(Succeed())
+
+			config, err := server.ReadServerConfig(configPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			apps := server.ProcessApplicationConfigs(config)
+			app, err := apps.GetApplication("sample-ear")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app.WebExtensions).To(Equal([]server.WebExtension{
+				{ModuleName: "shop", ContextRoot: "/shop"},
+				{ModuleName: "admin", ContextRoot: "/console"},
+			}))
+		})
+	})
+
+	when("merging web module extensions", func() {
+		it("replaces extensions for the same module and adds the others", func() {
+			merged := server.MergeWebExtensions(
+				[]server.WebExtension{{ModuleName: "shop", ContextRoot: "/shop"}},
+				[]server.WebExtension{{ModuleName: "shop", ContextRoot: "/store"}, {ModuleName: "admin", ContextRoot: "/admin"}},
+			)
+			Expect(merged).To(Equal([]server.WebExtension{
+				{ModuleName: "shop", ContextRoot: "/store"},
+				{ModuleName: "admin", ContextRoot: "/admin"},
+			}))
+		})
+
+		it("returns the extensions if there are no overrides", func() {
+			Expect(server.MergeWebExtensions(nil, nil)).To(BeNil())
+		})
+	})
+
+	when("adding common library references", func() {
+		it("reads the classloader of the application", func() {
+			configPath := filepath.Join(testPath, "server.xml")
+			Expect(os.WriteFile(configPath, []byte(`<server>
+  <application id="app" location="app.war">
+    <classloader commonLibraryRef="vendor-sdk"/>
+  </application>
+</server>`), 0644)).To(Succeed())
+
+			config, err := server.ReadServerConfig(configPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			apps := server.ProcessApplicationConfigs(config)
+			app, err := apps.GetApplication("app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app.Classloader.CommonLibraryRef).To(Equal("vendor-sdk"))
+		})
+
+		it("keeps the libraries already referenced", func() {
+			app := server.ApplicationConfig{Classloader: server.Classloader{CommonLibraryRef: "vendor-sdk, logging"}}
+			app.AddCommonLibraryRef("lib")
+			Expect(app.Classloader.CommonLibraryRef).To(Equal("vendor-sdk,logging,lib"))
+
+			app.AddCommonLibraryRef("logging")
+			Expect(app.Classloader.CommonLibraryRef).To(Equal("vendor-sdk,logging,lib"))
+		})
 	})
 }
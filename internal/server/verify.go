@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// appStartedPattern matches the messages logged when an application starts or is updated
+var appStartedPattern = regexp.MustCompile(`^CWWKZ000[13]I: (?:The )?[Aa]pplication (\S+) `)
+
+// GetConfiguredApplications returns the sorted names of the applications configured for the server, including those in
+// its dropins directory. Applications named using variables are left out, as their names are only known to the server.
+func GetConfiguredApplications(serverPath string) ([]string, error) {
+	names := map[string]bool{}
+
+	configs, err := GetServerConfigs(serverPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get server configs\n%w", err)
+	}
+	for _, configPath := range configs {
+		config, err := ReadServerConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read config\n%w", err)
+		}
+		for _, apps := range [][]ApplicationConfig{
+			config.Applications,
+			config.WebApplications,
+			config.EnterpriseApplications,
+			config.SpringBootApplications,
+		} {
+			for _, app := range apps {
+				if name := app.applicationName(); name != "" && !strings.Contains(name, "${") {
+					names[name] = true
+				}
+			}
+		}
+	}
+
+	dropins, err := os.ReadDir(filepath.Join(serverPath, "dropins"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read dropins directory\n%w", err)
+	}
+	for _, dropin := range dropins {
+		if ext := filepath.Ext(dropin.Name()); ext != "" && !strings.HasPrefix(dropin.Name(), ".") {
+			names[strings.TrimSuffix(dropin.Name(), ext)] = true
+		}
+	}
+
+	var apps []string
+	for name := range names {
+		apps = append(apps, name)
+	}
+	sort.Strings(apps)
+	return apps, nil
+}
+
+// applicationName returns the name the server gives the application, which defaults to its id and then to the name of
+// the file it is deployed from
+func (a ApplicationConfig) applicationName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	if a.Id != "" {
+		return a.Id
+	}
+	location := filepath.Base(a.Location)
+	return strings.TrimSuffix(location, filepath.Ext(location))
+}
+
+// GetStartedApplications returns the names of the applications the server logged as started.
+func GetStartedApplications(messages []Message) []string {
+	var apps []string
+	for _, message := range messages {
+		if match := appStartedPattern.FindStringSubmatch(message.Text); match != nil {
+			apps = append(apps, match[1])
+		}
+	}
+	return apps
+}
+
+// GetFFDCIncidents returns the FFDC incident reports written to the logs directory of the server.
+func GetFFDCIncidents(logsPath string) ([]string, error) {
+	incidents, err := filepath.Glob(filepath.Join(logsPath, "ffdc", "ffdc_*.log"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list FFDC incidents\n%w", err)
+	}
+	return incidents, nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testVerify(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect     = NewWithT(t).Expect
+		serverPath string
+	)
+
+	it.Before(func() {
+		var err error
+		serverPath, err = os.MkdirTemp("", "verify")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(serverPath)).To(Succeed())
+	})
+
+	it("gets the applications configured for the server", func() {
+		Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte(`<server>
+  <application id="app" name="store" location="store.war"/>
+  <webApplication id="admin" location="admin.war"/>
+  <enterpriseApplication location="apps/billing.ear"/>
+  <springBootApplication location="${app.location}"/>
+</server>`), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(serverPath, "configDropins", "overrides"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(serverPath, "configDropins", "overrides", "app.xml"), []byte(`<server>
+  <application id="app" name="store" location="store-2.war"/>
+</server>`), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(serverPath, "dropins"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(serverPath, "dropins", "search.war"), []byte{}, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(serverPath, "dropins", ".hidden.war"), []byte{}, 0644)).To(Succeed())
+
+		apps, err := server.GetConfiguredApplications(serverPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apps).To(Equal([]string{"admin", "billing", "search", "store"}))
+	})
+
+	it("gets the applications the server started", func() {
+		messages := []server.Message{
+			{Id: "CWWKZ0001I", Text: "CWWKZ0001I: Application store started in 1.234 seconds."},
+			{Id: "CWWKZ0003I", Text: "CWWKZ0003I: The application admin updated in 0.5 seconds."},
+			{Id: "CWWKZ0003I", Text: "CWWKZ0003I: Application billing updated in 0.5 seconds."},
+			{Id: "CWWKF0011I", Text: "CWWKF0011I: The defaultServer server is ready to run a smarter planet."},
+		}
+
+		Expect(server.GetStartedApplications(messages)).To(Equal([]string{"store", "admin", "billing"}))
+	})
+
+	it("gets the FFDC incidents", func() {
+		Expect(os.MkdirAll(filepath.Join(serverPath, "logs", "ffdc"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(serverPath, "logs", "ffdc", "ffdc_24.01.01_00.00.00.0.log"), []byte{}, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(serverPath, "logs", "ffdc", "incidentSummary_24.01.01_00.00.00.0.log"), []byte{}, 0644)).To(Succeed())
+
+		incidents, err := server.GetFFDCIncidents(filepath.Join(serverPath, "logs"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(incidents).To(Equal([]string{filepath.Join(serverPath, "logs", "ffdc", "ffdc_24.01.01_00.00.00.0.log")}))
+	})
+
+	it("has no FFDC incidents if the server did not write any", func() {
+		incidents, err := server.GetFFDCIncidents(filepath.Join(serverPath, "logs"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(incidents).To(BeEmpty())
+	})
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const classFileMagic uint32 = 0xCAFEBABE
+
+// ReadClassConstants returns the UTF-8 entries of a class file's constant pool. These contain the names of all classes,
+// packages and annotation types that the class refers to, e.g. `jakarta/ws/rs/Path` or `Ljakarta/ws/rs/Path;`.
+func ReadClassConstants(r io.Reader) ([]string, error) {
+	reader := bufio.NewReader(r)
+
+	var header struct {
+		Magic     uint32
+		Minor     uint16
+		Major     uint16
+		PoolCount uint16
+	}
+	if err := binary.Read(reader, binary.BigEndian, &header); err != nil {
+		return nil, fmt.Errorf("unable to read class file header\n%w", err)
+	}
+	if header.Magic != classFileMagic {
+		return nil, fmt.Errorf("not a class file")
+	}
+
+	var constants []string
+	// Constant pool indexes start at 1 and long and double entries take up two slots
+	for i := uint16(1); i < header.PoolCount; i++ {
+		tag, err := reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read constant pool tag\n%w", err)
+		}
+
+		var skip int
+		switch tag {
+		case 1: // Utf8
+			var length uint16
+			if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+				return nil, fmt.Errorf("unable to read constant length\n%w", err)
+			}
+			value := make([]byte, length)
+			if _, err := io.ReadFull(reader, value); err != nil {
+				return nil, fmt.Errorf("unable to read constant\n%w", err)
+			}
+			constants = append(constants, string(value))
+			continue
+		case 7, 8, 16, 19, 20: // Class, String, MethodType, Module, Package
+			skip = 2
+		case 15: // MethodHandle
+			skip = 3
+		case 3, 4, 9, 10, 11, 12, 17, 18: // Integer, Float, Fieldref, Methodref, InterfaceMethodref, NameAndType, Dynamic, InvokeDynamic
+			skip = 4
+		case 5, 6: // Long, Double
+			skip = 8
+			i++
+		default:
+			return nil, fmt.Errorf("unknown constant pool tag %d", tag)
+		}
+
+		if _, err := reader.Discard(skip); err != nil {
+			return nil, fmt.Errorf("unable to read constant\n%w", err)
+		}
+	}
+
+	return constants, nil
+}
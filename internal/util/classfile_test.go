@@ -0,0 +1,69 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+// newClassFile returns a class file whose constant pool holds a long constant followed by the given UTF-8 constants
+func newClassFile(constants ...string) []byte {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.BigEndian, uint32(0xCAFEBABE))
+	_ = binary.Write(buf, binary.BigEndian, uint16(0))
+	_ = binary.Write(buf, binary.BigEndian, uint16(61))
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(constants)+4))
+
+	buf.WriteByte(5) // Long
+	_ = binary.Write(buf, binary.BigEndian, int64(42))
+	buf.WriteByte(7) // Class
+	_ = binary.Write(buf, binary.BigEndian, uint16(4))
+	for _, constant := range constants {
+		buf.WriteByte(1) // Utf8
+		_ = binary.Write(buf, binary.BigEndian, uint16(len(constant)))
+		buf.WriteString(constant)
+	}
+	return buf.Bytes()
+}
+
+func testClassFile(t *testing.T, when spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	it("reads the UTF-8 constants", func() {
+		constants, err := util.ReadClassConstants(bytes.NewReader(newClassFile("com/example/Resource", "Ljakarta/ws/rs/Path;")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(constants).To(Equal([]string{"com/example/Resource", "Ljakarta/ws/rs/Path;"}))
+	})
+
+	it("fails if the file is not a class file", func() {
+		_, err := util.ReadClassConstants(bytes.NewReader([]byte("PK\x03\x04 not a class")))
+		Expect(err).To(MatchError("not a class file"))
+	})
+
+	it("fails if the constant pool is truncated", func() {
+		class := newClassFile("com/example/Resource")
+		_, err := util.ReadClassConstants(bytes.NewReader(class[:len(class)-4]))
+		Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// WebModule is a web module of an enterprise application.
+type WebModule struct {
+	// URI is the path of the module in the enterprise application, e.g. `web.war`
+	URI string `xml:"web-uri"`
+
+	// ContextRoot is the context root declared for the module in `application.xml`
+	ContextRoot string `xml:"context-root"`
+}
+
+// Name returns the module name Liberty uses for the web module, which is its URI without the `.war` extension.
+func (m WebModule) Name() string {
+	return strings.TrimSuffix(path.Base(m.URI), ".war")
+}
+
+// IsEnterpriseApplication returns true if the application at the path contains `META-INF/application.xml`. The path
+// may be an expanded application or an archive.
+func IsEnterpriseApplication(appPath string) (bool, error) {
+	_, found, err := readApplicationXML(appPath)
+	if err != nil {
+		return false, fmt.Errorf("unable to check application.xml\n%w", err)
+	}
+	return found, nil
+}
+
+// GetWebModules returns the web modules declared in `META-INF/application.xml` of the enterprise application. The path
+// may be an expanded application or an archive.
+func GetWebModules(appPath string) ([]WebModule, error) {
+	content, found, err := readApplicationXML(appPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read application.xml\n%w", err)
+	} else if !found {
+		return nil, fmt.Errorf("unable to read application.xml\n%w", fs.ErrNotExist)
+	}
+
+	var descriptor struct {
+		Modules []struct {
+			Web *WebModule `xml:"web"`
+		} `xml:"module"`
+	}
+	if err := xml.Unmarshal(content, &descriptor); err != nil {
+		return nil, fmt.Errorf("unable to parse application.xml\n%w", err)
+	}
+
+	var modules []WebModule
+	for _, module := range descriptor.Modules {
+		if module.Web != nil {
+			modules = append(modules, WebModule{
+				URI:         strings.TrimSpace(module.Web.URI),
+				ContextRoot: strings.TrimSpace(module.Web.ContextRoot),
+			})
+		}
+	}
+	return modules, nil
+}
+
+func readApplicationXML(appPath string) ([]byte, bool, error) {
+	isDir, err := sherpa.DirExists(appPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var fsys fs.FS
+	if isDir {
+		fsys = os.DirFS(appPath)
+	} else {
+		reader, err := zip.OpenReader(appPath)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, zip.ErrFormat) {
+			return nil, false, nil
+		} else if err != nil {
+			return nil, false, err
+		}
+		defer reader.Close()
+		fsys = reader
+	}
+
+	content, err := fs.ReadFile(fsys, "META-INF/application.xml")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return content, true, nil
+}
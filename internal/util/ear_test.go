@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testEAR(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect  = NewWithT(t).Expect
+		appPath string
+	)
+
+	it.Before(func() {
+		var err error
+		appPath, err = os.MkdirTemp("", "ear")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(appPath)).To(Succeed())
+	})
+
+	it("checks for application.xml", func() {
+		isEAR, err := util.IsEnterpriseApplication(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isEAR).To(BeFalse())
+
+		Expect(os.MkdirAll(filepath.Join(appPath, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "META-INF", "application.xml"), []byte("<application/>"), 0644)).To(Succeed())
+
+		isEAR, err = util.IsEnterpriseApplication(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(isEAR).To(BeTrue())
+	})
+
+	it("reads the web modules from application.xml", func() {
+		Expect(os.MkdirAll(filepath.Join(appPath, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "META-INF", "application.xml"), []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<application xmlns="https://jakarta.ee/xml/ns/jakartaee" version="10">
+  <module>
+    <web>
+      <web-uri>shop.war</web-uri>
+      <context-root>/shop</context-root>
+    </web>
+  </module>
+  <module>
+    <ejb>orders.jar</ejb>
+  </module>
+  <module>
+    <web>
+      <web-uri>modules/admin.war</web-uri>
+    </web>
+  </module>
+</application>`), 0644)).To(Succeed())
+
+		modules, err := util.GetWebModules(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modules).To(Equal([]util.WebModule{
+			{URI: "shop.war", ContextRoot: "/shop"},
+			{URI: "modules/admin.war"},
+		}))
+		Expect(modules[0].Name()).To(Equal("shop"))
+		Expect(modules[1].Name()).To(Equal("admin"))
+	})
+
+	it("fails if application.xml is missing", func() {
+		_, err := util.GetWebModules(appPath)
+		Expect(err).To(HaveOccurred())
+	})
+}
@@ -27,7 +27,13 @@ func TestUnit(t *testing.T) {
 	suite := spec.New("util", spec.Report(report.Terminal{}))
 	suite("App", testApp)
 	suite("Archive", testArchive)
+	suite("ClassFile", testClassFile)
+	suite("EAR", testEAR)
 	suite("File", testFile)
 	suite("JVM", testJVM)
+	suite("Scan", testScan)
+	suite("ServerPackage", testServerPackage)
+	suite("SpringBoot", testSpringBoot)
+	suite("WebAppLibs", testWebAppLibs)
 	suite.Run(t)
 }
@@ -24,42 +24,98 @@ import (
 	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/paketo-buildpacks/libpak/effect"
 	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
-// DetectJVMName returns the value for java.vm.name.
-func DetectJVMName(executor effect.Executor) (string, error) {
+// JVM describes the Java runtime that builds and runs the application.
+type JVM struct {
+	Vendor string
+	VMName string
+	// Version is the feature version of Java, such as 17
+	Version int
+	// JDK is true if the runtime is a JDK rather than a JRE
+	JDK bool
+}
+
+// Name returns `OpenJ9` or `OpenJDK` for the HotSpot JVMs, or an empty string for other JVMs.
+func (j JVM) Name() string {
+	if strings.Contains(j.VMName, "OpenJ9") {
+		return "OpenJ9"
+	} else if strings.Contains(j.VMName, "OpenJDK") {
+		return "OpenJDK"
+	}
+	return ""
+}
+
+func (j JVM) String() string {
+	kind := "JRE"
+	if j.JDK {
+		kind = "JDK"
+	}
+	return fmt.Sprintf("%s %s %d (%s)", j.Vendor, j.VMName, j.Version, kind)
+}
+
+// DetectJVM describes the JVM on the path from its system properties. Returns an error if the Java version cannot be
+// determined.
+func DetectJVM(executor effect.Executor) (JVM, error) {
 	buf := &bytes.Buffer{}
 
+	// The settings are printed to stderr, along with the version
 	if err := executor.Execute(effect.Execution{
 		Command: "java",
 		Args:    []string{"-XshowSettings:properties", "-version"},
 		Stdout:  buf,
+		Stderr:  buf,
 	}); err != nil {
-		return "", fmt.Errorf("unable to detect JVM name\n%w", err)
+		return JVM{}, fmt.Errorf("unable to detect JVM\n%w", err)
 	}
 
+	properties := map[string]string{}
 	scanner := bufio.NewScanner(buf)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "java.vm.name") {
-			if strings.Contains(line, "OpenJ9") {
-				return "OpenJ9", nil
-			} else if strings.Contains(line, "OpenJDK") {
-				return "OpenJDK", nil
-			}
-			break
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
 		}
 	}
-
 	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("unable to read JVM name\n%w", err)
+		return JVM{}, fmt.Errorf("unable to read JVM properties\n%w", err)
 	}
 
-	return "", nil
+	version := properties["java.specification.version"]
+	if version == "" {
+		version = properties["java.version"]
+	}
+
+	jvm := JVM{
+		Vendor:  properties["java.vendor"],
+		VMName:  properties["java.vm.name"],
+		Version: javaFeatureVersion(version),
+	}
+	if jvm.Version == 0 {
+		return JVM{}, fmt.Errorf("unable to determine the Java version from the JVM properties")
+	}
+	if javaHome := properties["java.home"]; javaHome != "" {
+		if _, err := os.Stat(filepath.Join(javaHome, "bin", "javac")); err == nil {
+			jvm.JDK = true
+		}
+	}
+	return jvm, nil
+}
+
+// javaFeatureVersion returns the feature version of a Java version such as `1.8.0_352` or `17.0.8`
+func javaFeatureVersion(version string) int {
+	version = strings.TrimPrefix(version, "1.")
+	feature, _, _ := strings.Cut(version, ".")
+	v, err := strconv.Atoi(feature)
+	if err != nil {
+		return 0
+	}
+	return v
 }
 
 type SharedClassCache struct {
@@ -75,6 +131,15 @@ type SharedClassCacheOptions struct {
 	SizeMB        int
 	NumIterations int
 	Trim          bool
+
+	// AutoIterations cycles the server until the fill ratio of the cache stops growing instead of NumIterations times
+	AutoIterations bool
+	// TrainingURLs are requested once the server is started, so that the classes serving them are cached too
+	TrainingURLs []string
+	// TrainingScript is run once the server is started to send a training workload to the application
+	TrainingScript string
+	// CDS builds a dynamic CDS archive for a HotSpot JVM instead of an OpenJ9 shared class cache
+	CDS bool
 }
 
 func (scc SharedClassCache) GetFillRatio() (float64, error) {
@@ -17,6 +17,10 @@
 package util_test
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/paketo-buildpacks/liberty/internal/util"
 	"github.com/paketo-buildpacks/libpak/effect"
 	"github.com/paketo-buildpacks/libpak/effect/mocks"
@@ -37,10 +41,12 @@ func testJVM(t *testing.T, when spec.G, it spec.S) {
 			executor := &mocks.Executor{}
 			executor.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
 				arg := args.Get(0).(effect.Execution)
-				_, err := arg.Stdout.Write([]byte(`
+				_, err := arg.Stderr.Write([]byte(`
 						java.vendor = IBM Corporation
 						java.vendor.url = https://www.ibm.com/semeru-runtimes
 						java.vendor.url.bug = https://github.com/ibmruntimes/Semeru-Runtimes/issues
+						java.home = /opt/java/openjdk
+						java.specification.version = 11
 						java.vendor.version = 11.0.16.1
 						java.version = 11.0.16.1
 						java.version.date = 2022-08-12
@@ -50,14 +56,17 @@ func testJVM(t *testing.T, when spec.G, it spec.S) {
 				)
 				Expect(err).ToNot(HaveOccurred())
 			}).Return(nil)
-			Expect(util.DetectJVMName(executor)).To(Equal("OpenJ9"))
+			jvm, err := util.DetectJVM(executor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jvm).To(Equal(util.JVM{Vendor: "IBM Corporation", VMName: "Eclipse OpenJ9 VM", Version: 11}))
+			Expect(jvm.Name()).To(Equal("OpenJ9"))
 		})
 
 		it("detects the Bellsoft Liberica JVM", func() {
 			executor := &mocks.Executor{}
 			executor.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
 				arg := args.Get(0).(effect.Execution)
-				_, err := arg.Stdout.Write([]byte(`
+				_, err := arg.Stderr.Write([]byte(`
 						java.vendor = BellSoft
 						java.vendor.url = https://bell-sw.com/
 						java.vendor.url.bug = https://bell-sw.com/support
@@ -71,7 +80,45 @@ func testJVM(t *testing.T, when spec.G, it spec.S) {
 				)
 				Expect(err).ToNot(HaveOccurred())
 			}).Return(nil)
-			Expect(util.DetectJVMName(executor)).To(Equal("OpenJDK"))
+			jvm, err := util.DetectJVM(executor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jvm.Vendor).To(Equal("BellSoft"))
+			Expect(jvm.Version).To(Equal(11))
+			Expect(jvm.Name()).To(Equal("OpenJDK"))
+		})
+
+		it("detects a Java 8 JDK", func() {
+			javaHome := t.TempDir()
+			Expect(os.MkdirAll(filepath.Join(javaHome, "bin"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(javaHome, "bin", "javac"), []byte{}, 0755)).To(Succeed())
+
+			executor := &mocks.Executor{}
+			executor.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
+				arg := args.Get(0).(effect.Execution)
+				_, err := fmt.Fprintf(arg.Stderr, `
+						java.home = %s
+						java.vendor = Eclipse Adoptium
+						java.version = 1.8.0_352
+						java.vm.name = OpenJDK 64-Bit Server VM`, javaHome)
+				Expect(err).ToNot(HaveOccurred())
+			}).Return(nil)
+
+			jvm, err := util.DetectJVM(executor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jvm).To(Equal(util.JVM{Vendor: "Eclipse Adoptium", VMName: "OpenJDK 64-Bit Server VM", Version: 8, JDK: true}))
+		})
+
+		it("fails if the Java version is unknown", func() {
+			executor := &mocks.Executor{}
+			executor.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
+				_, err := args.Get(0).(effect.Execution).Stderr.Write([]byte(`
+						java.vendor = Eclipse Adoptium
+						java.vm.name = OpenJDK 64-Bit Server VM`))
+				Expect(err).ToNot(HaveOccurred())
+			}).Return(nil)
+
+			_, err := util.DetectJVM(executor)
+			Expect(err).To(MatchError("unable to determine the Java version from the JVM properties"))
 		})
 	})
 }
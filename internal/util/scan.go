@@ -0,0 +1,314 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	JakartaEENamespace = "https://jakarta.ee/xml/ns/jakartaee"
+	JavaEENamespace    = "http://xmlns.jcp.org/xml/ns/javaee"
+	LegacyEENamespace  = "http://java.sun.com/xml/ns/javaee"
+)
+
+// javaxEEPackages are the `javax` packages that belong to Java EE rather than Java SE.
+var javaxEEPackages = []string{
+	"javax/annotation/security/",
+	"javax/batch/",
+	"javax/ejb/",
+	"javax/enterprise/",
+	"javax/faces/",
+	"javax/inject/",
+	"javax/jms/",
+	"javax/json/",
+	"javax/mail/",
+	"javax/persistence/",
+	"javax/security/enterprise/",
+	"javax/servlet/",
+	"javax/transaction/",
+	"javax/validation/",
+	"javax/websocket/",
+	"javax/ws/rs/",
+}
+
+// ApplicationScan holds what was found when inspecting the contents of an application.
+type ApplicationScan struct {
+	// WebXMLVersion and WebXMLNamespace are read from the root element of `WEB-INF/web.xml`
+	WebXMLVersion   string
+	WebXMLNamespace string
+
+	// ApplicationXMLVersion and ApplicationXMLNamespace are read from the root element of `META-INF/application.xml`
+	ApplicationXMLVersion   string
+	ApplicationXMLNamespace string
+
+	// JakartaReferences is true if any class refers to a `jakarta.*` type
+	JakartaReferences bool
+
+	// JavaxReferences is true if any class refers to a Java EE `javax.*` type
+	JavaxReferences bool
+
+	HasBeansXML           bool
+	HasPersistenceXML     bool
+	HasMicroProfileConfig bool
+
+	// References are the Jakarta/Java EE and MicroProfile types referenced by the application classes, including
+	// annotations, e.g. `jakarta.ws.rs.Path`
+	References map[string]bool
+}
+
+// HasReference returns true if the application classes refer to the type, e.g. `jakarta.ws.rs.Path`.
+func (s ApplicationScan) HasReference(name string) bool {
+	return s.References[name]
+}
+
+// HasReferencePrefix returns true if the application classes refer to any type starting with the prefix, e.g.
+// `org.eclipse.microprofile.openapi.`.
+func (s ApplicationScan) HasReferencePrefix(prefix string) bool {
+	for reference := range s.References {
+		if strings.HasPrefix(reference, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEnterpriseApplication returns true if an `application.xml` descriptor was found.
+func (s ApplicationScan) IsEnterpriseApplication() bool {
+	return s.ApplicationXMLVersion != "" || s.ApplicationXMLNamespace != ""
+}
+
+// PlatformVersion returns the Jakarta/Java EE version the application was written for, e.g. `8` or `10`. The
+// deployment descriptor versions are preferred, followed by their namespaces and finally the packages referenced by the
+// application classes. Returns the empty string if the version cannot be determined.
+func (s ApplicationScan) PlatformVersion() string {
+	if v, ok := map[string]string{
+		"7": "7", "8": "8", "9": "9.1", "10": "10", "11": "11",
+	}[s.ApplicationXMLVersion]; ok {
+		return v
+	}
+
+	if v, ok := map[string]string{
+		"3.1": "7", "4.0": "8", "5.0": "9.1", "6.0": "10", "6.1": "11",
+	}[s.WebXMLVersion]; ok {
+		return v
+	}
+
+	for _, ns := range []string{s.ApplicationXMLNamespace, s.WebXMLNamespace} {
+		switch ns {
+		case JakartaEENamespace:
+			return "10"
+		case JavaEENamespace:
+			return "8"
+		case LegacyEENamespace:
+			return "7"
+		}
+	}
+
+	if s.JakartaReferences {
+		return "10"
+	} else if s.JavaxReferences {
+		return "8"
+	}
+
+	return ""
+}
+
+// ScanApplication inspects the application at the given path. The path may be an expanded application or a directory
+// containing compiled web or enterprise archives.
+func ScanApplication(appPath string) (ApplicationScan, error) {
+	scan := ApplicationScan{}
+
+	isPackage, err := IsJvmApplicationPackage(appPath)
+	if err != nil {
+		return scan, err
+	}
+	if isPackage {
+		if err := scan.scanFS(os.DirFS(appPath)); err != nil {
+			return scan, fmt.Errorf("unable to scan application at %s\n%w", appPath, err)
+		}
+		return scan, nil
+	}
+
+	apps, err := GetApps(appPath)
+	if err != nil {
+		return scan, fmt.Errorf("unable to find applications in %s\n%w", appPath, err)
+	}
+
+	for _, app := range apps {
+		if err := scan.scanPath(app); err != nil {
+			return scan, fmt.Errorf("unable to scan application at %s\n%w", app, err)
+		}
+	}
+
+	return scan, nil
+}
+
+func (s *ApplicationScan) scanPath(app string) error {
+	info, err := os.Stat(app)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return s.scanFS(os.DirFS(app))
+	}
+
+	reader, err := zip.OpenReader(app)
+	if errors.Is(err, zip.ErrFormat) {
+		// ignore archives that cannot be read, Liberty reports these when the application is deployed
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return s.scanFS(reader)
+}
+
+func (s *ApplicationScan) scanFS(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		name := path.Base(file)
+		parent := path.Base(path.Dir(file))
+
+		switch {
+		case file == "WEB-INF/web.xml":
+			s.WebXMLVersion, s.WebXMLNamespace, err = readDescriptorVersion(fsys, file)
+			return err
+		case file == "META-INF/application.xml":
+			s.ApplicationXMLVersion, s.ApplicationXMLNamespace, err = readDescriptorVersion(fsys, file)
+			return err
+		case name == "beans.xml" && (parent == "WEB-INF" || parent == "META-INF"):
+			s.HasBeansXML = true
+		case name == "persistence.xml" && parent == "META-INF":
+			s.HasPersistenceXML = true
+		case name == "microprofile-config.properties" && parent == "META-INF":
+			s.HasMicroProfileConfig = true
+		case strings.HasSuffix(name, ".class"):
+			return s.scanClass(fsys, file)
+		case strings.HasSuffix(name, ".jar") || strings.HasSuffix(name, ".war"):
+			return s.scanArchive(fsys, file)
+		}
+
+		return nil
+	})
+}
+
+func (s *ApplicationScan) scanClass(fsys fs.FS, file string) error {
+	in, err := fsys.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	constants, err := ReadClassConstants(in)
+	if err != nil {
+		// ignore files that are not valid class files rather than failing the build
+		return nil
+	}
+
+	for _, constant := range constants {
+		// class names are `a/b/C` while descriptors, which are used for annotations, are `La/b/C;`
+		if strings.HasPrefix(constant, "L") && strings.HasSuffix(constant, ";") {
+			constant = strings.TrimSuffix(strings.TrimPrefix(constant, "L"), ";")
+		}
+
+		switch {
+		case strings.HasPrefix(constant, "jakarta/"):
+			s.JakartaReferences = true
+		case isJavaxEEType(constant):
+			s.JavaxReferences = true
+		case strings.HasPrefix(constant, "org/eclipse/microprofile/"):
+		default:
+			continue
+		}
+
+		if s.References == nil {
+			s.References = map[string]bool{}
+		}
+		s.References[strings.ReplaceAll(constant, "/", ".")] = true
+	}
+
+	return nil
+}
+
+func isJavaxEEType(name string) bool {
+	for _, pkg := range javaxEEPackages {
+		if strings.HasPrefix(name, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ApplicationScan) scanArchive(fsys fs.FS, file string) error {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return err
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		// ignore nested archives that cannot be read
+		return nil
+	}
+
+	return s.scanFS(reader)
+}
+
+// readDescriptorVersion returns the version and namespace attributes of the root element of a deployment descriptor
+func readDescriptorVersion(fsys fs.FS, file string) (string, string, error) {
+	in, err := fsys.Open(file)
+	if err != nil {
+		return "", "", err
+	}
+	defer in.Close()
+
+	decoder := xml.NewDecoder(in)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", "", nil
+		} else if err != nil {
+			return "", "", fmt.Errorf("unable to parse %s\n%w", file, err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			var version string
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "version" && attr.Name.Space == "" {
+					version = attr.Value
+				}
+			}
+			return version, start.Name.Space, nil
+		}
+	}
+}
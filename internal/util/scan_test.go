@@ -0,0 +1,195 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+// newZip returns a zip archive holding the given files
+func newZip(files map[string][]byte) []byte {
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := w.Write(content); err != nil {
+			panic(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func testScan(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect  = NewWithT(t).Expect
+		appPath string
+	)
+
+	it.Before(func() {
+		var err error
+		appPath, err = os.MkdirTemp("", "scan")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(appPath)).To(Succeed())
+	})
+
+	writeFile := func(content []byte, elem ...string) {
+		file := filepath.Join(append([]string{appPath}, elem...)...)
+		Expect(os.MkdirAll(filepath.Dir(file), 0755)).To(Succeed())
+		Expect(os.WriteFile(file, content, 0644)).To(Succeed())
+	}
+
+	when("scanning an expanded web application", func() {
+		it("reads the web.xml version and namespace", func() {
+			writeFile([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<web-app xmlns="https://jakarta.ee/xml/ns/jakartaee" version="6.0"></web-app>`), "WEB-INF", "web.xml")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.WebXMLVersion).To(Equal("6.0"))
+			Expect(scan.WebXMLNamespace).To(Equal(util.JakartaEENamespace))
+			Expect(scan.PlatformVersion()).To(Equal("10"))
+			Expect(scan.IsEnterpriseApplication()).To(BeFalse())
+		})
+
+		it("finds jakarta references in classes and libraries", func() {
+			writeFile(newClassFile("com/example/Main", "java/lang/Object"), "WEB-INF", "classes", "com", "example", "Main.class")
+			writeFile(newZip(map[string][]byte{
+				"com/example/lib/Resource.class": newClassFile("Ljakarta/ws/rs/Path;"),
+			}), "WEB-INF", "lib", "lib.jar")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.JakartaReferences).To(BeTrue())
+			Expect(scan.JavaxReferences).To(BeFalse())
+			Expect(scan.PlatformVersion()).To(Equal("10"))
+		})
+
+		it("records the Jakarta EE and MicroProfile types referenced by classes", func() {
+			writeFile(newClassFile(
+				"com/example/Resource",
+				"Ljakarta/ws/rs/Path;",
+				"jakarta/inject/Inject",
+				"Lorg/eclipse/microprofile/openapi/annotations/Operation;",
+				"java/lang/String",
+				"(Ljakarta/ws/rs/core/Response;)V",
+			), "WEB-INF", "classes", "com", "example", "Resource.class")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.References).To(Equal(map[string]bool{
+				"jakarta.ws.rs.Path":    true,
+				"jakarta.inject.Inject": true,
+				"org.eclipse.microprofile.openapi.annotations.Operation": true,
+			}))
+			Expect(scan.HasReference("jakarta.ws.rs.Path")).To(BeTrue())
+			Expect(scan.HasReference("java.lang.String")).To(BeFalse())
+			Expect(scan.HasReferencePrefix("org.eclipse.microprofile.openapi.")).To(BeTrue())
+			Expect(scan.HasReferencePrefix("org.eclipse.microprofile.health.")).To(BeFalse())
+		})
+
+		it("ignores javax packages that belong to Java SE", func() {
+			writeFile(newClassFile("javax/xml/parsers/DocumentBuilder", "javax/crypto/Cipher"), "WEB-INF", "classes", "Main.class")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.JavaxReferences).To(BeFalse())
+			Expect(scan.PlatformVersion()).To(BeEmpty())
+		})
+
+		it("finds javax references", func() {
+			writeFile(newClassFile("javax/servlet/http/HttpServlet"), "WEB-INF", "classes", "Main.class")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.JavaxReferences).To(BeTrue())
+			Expect(scan.PlatformVersion()).To(Equal("8"))
+		})
+
+		it("prefers the descriptor version over class references", func() {
+			writeFile([]byte(`<web-app xmlns="https://jakarta.ee/xml/ns/jakartaee" version="5.0"/>`), "WEB-INF", "web.xml")
+			writeFile(newClassFile("jakarta/servlet/http/HttpServlet"), "WEB-INF", "classes", "Main.class")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.PlatformVersion()).To(Equal("9.1"))
+		})
+
+		it("finds beans.xml, persistence.xml and microprofile-config.properties", func() {
+			writeFile([]byte{}, "WEB-INF", "beans.xml")
+			writeFile([]byte{}, "WEB-INF", "classes", "META-INF", "persistence.xml")
+			writeFile([]byte{}, "WEB-INF", "classes", "META-INF", "microprofile-config.properties")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.HasBeansXML).To(BeTrue())
+			Expect(scan.HasPersistenceXML).To(BeTrue())
+			Expect(scan.HasMicroProfileConfig).To(BeTrue())
+		})
+	})
+
+	when("scanning compiled artifacts", func() {
+		it("scans web archives", func() {
+			writeFile(newZip(map[string][]byte{
+				"WEB-INF/web.xml": []byte(`<web-app xmlns="http://xmlns.jcp.org/xml/ns/javaee" version="4.0"/>`),
+			}), "test.war")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.WebXMLVersion).To(Equal("4.0"))
+			Expect(scan.PlatformVersion()).To(Equal("8"))
+		})
+
+		it("scans enterprise archives and their modules", func() {
+			writeFile(newZip(map[string][]byte{
+				"META-INF/application.xml": []byte(`<application xmlns="https://jakarta.ee/xml/ns/jakartaee" version="11"/>`),
+				"web.war": newZip(map[string][]byte{
+					"WEB-INF/beans.xml": {},
+				}),
+			}), "test.ear")
+
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan.IsEnterpriseApplication()).To(BeTrue())
+			Expect(scan.HasBeansXML).To(BeTrue())
+			Expect(scan.PlatformVersion()).To(Equal("11"))
+		})
+
+		it("returns an empty scan if there are no applications", func() {
+			scan, err := util.ScanApplication(appPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scan).To(Equal(util.ApplicationScan{}))
+		})
+	})
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// serverConfigEntry matches the server.xml of a server in a `server package` archive, which is rooted at `wlp/usr` or
+// at `usr` for packages of the user directory only
+var serverConfigEntry = regexp.MustCompile(`^(?:wlp/)?usr/servers/([^/]+)/server\.xml$`)
+
+// serverPackageExtensions are the formats written by `server package`, including self-extracting jars
+var serverPackageExtensions = []string{".zip", ".jar", ".tar.gz", ".tgz", ".tar"}
+
+// ServerPackage is an archive created with `server package`.
+type ServerPackage struct {
+	// Path is the location of the archive
+	Path string
+
+	// Entries are the names of the files in the archive
+	Entries []string
+}
+
+// FindServerPackage returns the `server package` archive in the directory. Archives are recognised by the `server.xml`
+// of at least one server. Returns false if there is no server package.
+func FindServerPackage(dir string) (ServerPackage, bool, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return ServerPackage{}, false, nil
+	} else if err != nil {
+		return ServerPackage{}, false, fmt.Errorf("unable to read directory %s\n%w", dir, err)
+	}
+
+	var packages []ServerPackage
+	for _, file := range files {
+		if !file.Type().IsRegular() || !isServerPackageName(file.Name()) {
+			continue
+		}
+
+		pkg := ServerPackage{Path: filepath.Join(dir, file.Name())}
+		pkg.Entries, err = listArchive(pkg.Path)
+		if err != nil {
+			return ServerPackage{}, false, fmt.Errorf("unable to list %s\n%w", pkg.Path, err)
+		}
+		if len(pkg.Servers()) > 0 {
+			packages = append(packages, pkg)
+		}
+	}
+
+	if len(packages) > 1 {
+		var names []string
+		for _, pkg := range packages {
+			names = append(names, filepath.Base(pkg.Path))
+		}
+		return ServerPackage{}, false, fmt.Errorf("expected one server package but found several: %s", strings.Join(names, ", "))
+	} else if len(packages) == 0 {
+		return ServerPackage{}, false, nil
+	}
+	return packages[0], true, nil
+}
+
+// Servers returns the names of the servers in the package.
+func (p ServerPackage) Servers() []string {
+	var servers []string
+	for _, entry := range p.Entries {
+		if match := serverConfigEntry.FindStringSubmatch(entry); match != nil {
+			servers = append(servers, match[1])
+		}
+	}
+	return servers
+}
+
+// HasInstalledApps returns true if the server has web or enterprise archives in its `apps` or `dropins` directory.
+func (p ServerPackage) HasInstalledApps(serverName string) bool {
+	for _, entry := range p.Entries {
+		dir, name := path.Split(strings.TrimPrefix(entry, "wlp/"))
+		if dir != path.Join("usr", "servers", serverName, "apps")+"/" && dir != path.Join("usr", "servers", serverName, "dropins")+"/" {
+			continue
+		}
+		if strings.HasSuffix(name, ".war") || strings.HasSuffix(name, ".ear") {
+			return true
+		}
+	}
+	return false
+}
+
+// Extract extracts the package into the destination directory.
+func (p ServerPackage) Extract(destination string) error {
+	in, err := os.Open(p.Path)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", p.Path, err)
+	}
+	defer in.Close()
+
+	if err := Extract(in, destination, 0); err != nil {
+		return fmt.Errorf("unable to extract %s\n%w", p.Path, err)
+	}
+	return nil
+}
+
+func isServerPackageName(name string) bool {
+	for _, ext := range serverPackageExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// listArchive returns the names of the files in the archive. Files that are not valid archives have no entries.
+func listArchive(file string) ([]string, error) {
+	if strings.HasSuffix(file, ".zip") || strings.HasSuffix(file, ".jar") {
+		reader, err := zip.OpenReader(file)
+		if errors.Is(err, zip.ErrFormat) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		var entries []string
+		for _, f := range reader.File {
+			entries = append(entries, f.Name)
+		}
+		return entries, nil
+	}
+
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var r io.Reader = in
+	if !strings.HasSuffix(file, ".tar") {
+		gz, err := gzip.NewReader(in)
+		if errors.Is(err, gzip.ErrHeader) || errors.Is(err, io.EOF) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var entries []string
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return entries, nil
+		} else if errors.Is(err, tar.ErrHeader) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		entries = append(entries, strings.TrimPrefix(header.Name, "./"))
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testServerPackage(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect  = NewWithT(t).Expect
+		testDir string
+	)
+
+	it.Before(func() {
+		var err error
+		testDir, err = os.MkdirTemp("", "server-package")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(testDir)).To(Succeed())
+	})
+
+	it("finds a zip server package and lists its servers and apps", func() {
+		Expect(os.WriteFile(filepath.Join(testDir, "app.war"), newZip(map[string][]byte{
+			"WEB-INF/web.xml": {},
+		}), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(testDir, "server.zip"), newZip(map[string][]byte{
+			"wlp/usr/servers/defaultServer/server.xml":      []byte("<server/>"),
+			"wlp/usr/servers/defaultServer/apps/app.war":    {},
+			"wlp/usr/servers/otherServer/server.xml":        []byte("<server/>"),
+			"wlp/usr/servers/otherServer/apps/lib/util.jar": {},
+		}), 0644)).To(Succeed())
+
+		pkg, found, err := util.FindServerPackage(testDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(pkg.Path).To(Equal(filepath.Join(testDir, "server.zip")))
+		Expect(pkg.Servers()).To(ConsistOf("defaultServer", "otherServer"))
+		Expect(pkg.HasInstalledApps("defaultServer")).To(BeTrue())
+		Expect(pkg.HasInstalledApps("otherServer")).To(BeFalse())
+
+		Expect(pkg.Extract(testDir)).To(Succeed())
+		Expect(filepath.Join(testDir, "wlp", "usr", "servers", "defaultServer", "server.xml")).To(BeARegularFile())
+	})
+
+	it("finds a tar.gz package of the usr directory", func() {
+		buf := &bytes.Buffer{}
+		gz := gzip.NewWriter(buf)
+		writer := tar.NewWriter(gz)
+		for _, name := range []string{"usr/servers/defaultServer/server.xml", "usr/servers/defaultServer/dropins/app.ear"} {
+			Expect(writer.WriteHeader(&tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg})).To(Succeed())
+		}
+		Expect(writer.Close()).To(Succeed())
+		Expect(gz.Close()).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(testDir, "server.tar.gz"), buf.Bytes(), 0644)).To(Succeed())
+
+		pkg, found, err := util.FindServerPackage(testDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(pkg.Servers()).To(Equal([]string{"defaultServer"}))
+		Expect(pkg.HasInstalledApps("defaultServer")).To(BeTrue())
+	})
+
+	it("ignores archives that are not server packages", func() {
+		Expect(os.WriteFile(filepath.Join(testDir, "lib.jar"), newZip(map[string][]byte{"META-INF/MANIFEST.MF": {}}), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(testDir, "broken.zip"), []byte("not a zip"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(testDir, "broken.tar.gz"), []byte("not a tarball"), 0644)).To(Succeed())
+
+		_, found, err := util.FindServerPackage(testDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	it("fails if there are several server packages", func() {
+		for _, name := range []string{"one.zip", "two.jar"} {
+			Expect(os.WriteFile(filepath.Join(testDir, name), newZip(map[string][]byte{
+				"wlp/usr/servers/defaultServer/server.xml": []byte("<server/>"),
+			}), 0644)).To(Succeed())
+		}
+
+		_, _, err := util.FindServerPackage(testDir)
+		Expect(err).To(MatchError("expected one server package but found several: one.zip, two.jar"))
+	})
+}
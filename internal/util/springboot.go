@@ -0,0 +1,219 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/libjvm"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+const (
+	// SpringBootLibIndexFile is the file in a thin Spring Boot application that lists the libraries removed from it
+	SpringBootLibIndexFile = "META-INF/spring.lib.index.cache"
+
+	defaultSpringBootLib = "BOOT-INF/lib/"
+)
+
+// SpringBootLib is a library of a Spring Boot application.
+type SpringBootLib struct {
+	// Entry is the path of the library in the application, e.g. `BOOT-INF/lib/spring-core.jar`
+	Entry string
+
+	// Path is the location of the library on the filesystem
+	Path string
+
+	// SHA256 is the digest of the library contents
+	SHA256 string
+}
+
+// CachePath returns the path of the library relative to the root of a Liberty library index cache.
+func (l SpringBootLib) CachePath() string {
+	return filepath.Join(l.SHA256[:2], l.SHA256[2:]+".jar")
+}
+
+// GetSpringBootVersion returns the `Spring-Boot-Version` from the application manifest, or the empty string if the
+// application is not a Spring Boot application.
+func GetSpringBootVersion(appPath string) (string, error) {
+	m, err := libjvm.NewManifest(appPath)
+	if err != nil {
+		return "", fmt.Errorf("unable to read manifest\n%w", err)
+	}
+
+	version, _ := m.Get("Spring-Boot-Version")
+	return version, nil
+}
+
+// GetSpringBootLibs returns the libraries of the expanded Spring Boot application found in the `Spring-Boot-Lib`
+// directory, sorted by entry.
+func GetSpringBootLibs(appPath string) ([]SpringBootLib, error) {
+	m, err := libjvm.NewManifest(appPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read manifest\n%w", err)
+	}
+
+	libDir, ok := m.Get("Spring-Boot-Lib")
+	if !ok {
+		libDir = defaultSpringBootLib
+	}
+
+	jars, err := GetFiles(filepath.Join(appPath, libDir), "*.jar")
+	if err != nil {
+		return nil, fmt.Errorf("unable to find Spring Boot libraries\n%w", err)
+	}
+
+	var libs []SpringBootLib
+	for _, jar := range jars {
+		entry, err := filepath.Rel(appPath, jar)
+		if err != nil {
+			return nil, err
+		}
+		digest, err := sha256File(jar)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute digest of %s\n%w", jar, err)
+		}
+		libs = append(libs, SpringBootLib{Entry: filepath.ToSlash(entry), Path: jar, SHA256: digest})
+	}
+
+	sort.Slice(libs, func(i, j int) bool {
+		return libs[i].Entry < libs[j].Entry
+	})
+	return libs, nil
+}
+
+// WriteSpringBootThinApp writes the expanded Spring Boot application to the destination as a thin application jar.
+// The libraries are left out and listed in `META-INF/spring.lib.index.cache` instead, so that Liberty loads them from
+// its library index cache.
+func WriteSpringBootThinApp(appPath string, libs []SpringBootLib, destination string) error {
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return fmt.Errorf("unable to create directory for thin application\n%w", err)
+	}
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("unable to create thin application %s\n%w", destination, err)
+	}
+	defer out.Close()
+
+	writer := zip.NewWriter(out)
+
+	// The manifest is written first, as is expected of jar files
+	manifest := filepath.Join(appPath, "META-INF", "MANIFEST.MF")
+	if err := addZipEntry(writer, manifest, "META-INF/MANIFEST.MF"); err != nil {
+		return err
+	}
+
+	excluded := make(map[string]bool, len(libs))
+	index := &strings.Builder{}
+	for _, lib := range libs {
+		excluded[lib.Entry] = true
+		fmt.Fprintf(index, "/%s=%s\n", lib.Entry, lib.SHA256)
+	}
+
+	err = filepath.Walk(appPath, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || file == manifest {
+			return nil
+		}
+
+		entry, err := filepath.Rel(appPath, file)
+		if err != nil {
+			return err
+		}
+		entry = filepath.ToSlash(entry)
+		if excluded[entry] || entry == SpringBootLibIndexFile {
+			return nil
+		}
+
+		return addZipEntry(writer, file, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to write thin application\n%w", err)
+	}
+
+	w, err := writer.Create(SpringBootLibIndexFile)
+	if err != nil {
+		return fmt.Errorf("unable to create %s\n%w", SpringBootLibIndexFile, err)
+	}
+	if _, err := io.WriteString(w, index.String()); err != nil {
+		return fmt.Errorf("unable to write %s\n%w", SpringBootLibIndexFile, err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("unable to close thin application\n%w", err)
+	}
+	return nil
+}
+
+// CopySpringBootLibs copies the libraries into a Liberty library index cache at the destination.
+func CopySpringBootLibs(libs []SpringBootLib, destination string) error {
+	for _, lib := range libs {
+		in, err := os.Open(lib.Path)
+		if err != nil {
+			return fmt.Errorf("unable to open %s\n%w", lib.Path, err)
+		}
+		err = sherpa.CopyFile(in, filepath.Join(destination, lib.CachePath()))
+		in.Close()
+		if err != nil {
+			return fmt.Errorf("unable to copy %s\n%w", lib.Path, err)
+		}
+	}
+	return nil
+}
+
+func addZipEntry(writer *zip.Writer, file string, entry string) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer in.Close()
+
+	w, err := writer.Create(path.Clean(entry))
+	if err != nil {
+		return fmt.Errorf("unable to create entry %s\n%w", entry, err)
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		return fmt.Errorf("unable to write entry %s\n%w", entry, err)
+	}
+	return nil
+}
+
+func sha256File(file string) (string, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, in); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
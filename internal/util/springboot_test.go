@@ -0,0 +1,135 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSpringBoot(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect  = NewWithT(t).Expect
+		appPath string
+	)
+
+	it.Before(func() {
+		var err error
+		appPath, err = os.MkdirTemp("", "spring-boot")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(appPath, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "META-INF", "MANIFEST.MF"), []byte(`Manifest-Version: 1.0
+Main-Class: org.springframework.boot.loader.launch.JarLauncher
+Start-Class: com.example.Application
+Spring-Boot-Version: 3.2.1
+Spring-Boot-Classes: BOOT-INF/classes/
+Spring-Boot-Lib: BOOT-INF/lib/
+`), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(appPath, "BOOT-INF", "classes", "com", "example"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "BOOT-INF", "classes", "com", "example", "Application.class"), []byte("class"), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(appPath, "BOOT-INF", "lib"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "BOOT-INF", "lib", "spring-core.jar"), []byte("spring-core"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(appPath, "BOOT-INF", "lib", "spring-beans.jar"), []byte("spring-beans"), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(appPath)).To(Succeed())
+	})
+
+	it("reads the Spring Boot version", func() {
+		version, err := util.GetSpringBootVersion(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal("3.2.1"))
+	})
+
+	it("returns an empty version for other applications", func() {
+		Expect(os.WriteFile(filepath.Join(appPath, "META-INF", "MANIFEST.MF"), []byte("Main-Class: com.example.Main"), 0644)).To(Succeed())
+		version, err := util.GetSpringBootVersion(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(BeEmpty())
+	})
+
+	it("finds the libraries and their digests", func() {
+		libs, err := util.GetSpringBootLibs(appPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(libs).To(Equal([]util.SpringBootLib{
+			{
+				Entry:  "BOOT-INF/lib/spring-beans.jar",
+				Path:   filepath.Join(appPath, "BOOT-INF", "lib", "spring-beans.jar"),
+				SHA256: "e30904a4cc02a3e6e3fe6e33b7303fad4b309284a42cea3235296f1a579e78c1",
+			},
+			{
+				Entry:  "BOOT-INF/lib/spring-core.jar",
+				Path:   filepath.Join(appPath, "BOOT-INF", "lib", "spring-core.jar"),
+				SHA256: "a72402f5cb6c2ff8cc7bd4e817e894cded331a7bee543a8c7804c15d26d3f87d",
+			},
+		}))
+	})
+
+	it("writes a thin application", func() {
+		libs := []util.SpringBootLib{
+			{Entry: "BOOT-INF/lib/spring-beans.jar", SHA256: "aabbcc"},
+			{Entry: "BOOT-INF/lib/spring-core.jar", SHA256: "ddeeff"},
+		}
+		thinPath, err := os.MkdirTemp("", "spring-boot-thin")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(thinPath)
+
+		destination := filepath.Join(thinPath, "apps", "app.jar")
+		Expect(util.WriteSpringBootThinApp(appPath, libs, destination)).To(Succeed())
+
+		reader, err := zip.OpenReader(destination)
+		Expect(err).NotTo(HaveOccurred())
+		defer reader.Close()
+
+		var entries []string
+		for _, file := range reader.File {
+			entries = append(entries, file.Name)
+		}
+		Expect(entries).To(Equal([]string{
+			"META-INF/MANIFEST.MF",
+			"BOOT-INF/classes/com/example/Application.class",
+			"META-INF/spring.lib.index.cache",
+		}))
+
+		in, err := reader.Open("META-INF/spring.lib.index.cache")
+		Expect(err).NotTo(HaveOccurred())
+		index, err := io.ReadAll(in)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(index)).To(Equal("/BOOT-INF/lib/spring-beans.jar=aabbcc\n/BOOT-INF/lib/spring-core.jar=ddeeff\n"))
+	})
+
+	it("copies libraries to the library index cache", func() {
+		libs, err := util.GetSpringBootLibs(appPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		cachePath := filepath.Join(appPath, "cache")
+		Expect(util.CopySpringBootLibs(libs, cachePath)).To(Succeed())
+
+		Expect(libs[1].CachePath()).To(Equal(filepath.Join(libs[1].SHA256[:2], libs[1].SHA256[2:]+".jar")))
+		Expect(os.ReadFile(filepath.Join(cachePath, libs[1].CachePath()))).To(Equal([]byte("spring-core")))
+	})
+}
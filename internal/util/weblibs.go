@@ -0,0 +1,136 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const webAppLibDir = "WEB-INF/lib/"
+
+// WebAppLib is a library in the `WEB-INF/lib` directory of a web archive.
+type WebAppLib struct {
+	// Entry is the path of the library in the archive, e.g. `WEB-INF/lib/commons-lang3.jar`
+	Entry string
+
+	// SHA256 is the digest of the library contents
+	SHA256 string
+}
+
+// Name returns the file name of the library.
+func (l WebAppLib) Name() string {
+	return path.Base(l.Entry)
+}
+
+// GetWebAppLibs returns the libraries in the `WEB-INF/lib` directory of the web archive, sorted by entry. Returns nil if
+// the file is not a valid archive.
+func GetWebAppLibs(warPath string) ([]WebAppLib, error) {
+	reader, err := zip.OpenReader(warPath)
+	if errors.Is(err, zip.ErrFormat) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open %s\n%w", warPath, err)
+	}
+	defer reader.Close()
+
+	var libs []WebAppLib
+	for _, file := range reader.File {
+		if !isWebAppLib(file) {
+			continue
+		}
+
+		in, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("unable to open %s\n%w", file.Name, err)
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, in)
+		in.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute digest of %s\n%w", file.Name, err)
+		}
+		libs = append(libs, WebAppLib{Entry: file.Name, SHA256: hex.EncodeToString(hash.Sum(nil))})
+	}
+
+	sort.Slice(libs, func(i, j int) bool {
+		return libs[i].Entry < libs[j].Entry
+	})
+	return libs, nil
+}
+
+// ExtractWebAppLibs extracts the libraries from the web archive into the destination directory.
+func ExtractWebAppLibs(warPath string, libs []WebAppLib, destination string) error {
+	reader, err := zip.OpenReader(warPath)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", warPath, err)
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s\n%w", destination, err)
+	}
+
+	entries := make(map[string]bool, len(libs))
+	for _, lib := range libs {
+		entries[lib.Entry] = true
+	}
+
+	for _, file := range reader.File {
+		if !entries[file.Name] {
+			continue
+		}
+		if err := extractZipFile(file, filepath.Join(destination, path.Base(file.Name))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isWebAppLib returns true for jars directly in `WEB-INF/lib`, which are the ones on the application's classpath
+func isWebAppLib(file *zip.File) bool {
+	name := strings.TrimPrefix(file.Name, webAppLibDir)
+	return name != file.Name && !file.FileInfo().IsDir() && !strings.Contains(name, "/") && strings.HasSuffix(name, ".jar")
+}
+
+func extractZipFile(file *zip.File, destination string) error {
+	in, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", file.Name, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("unable to create %s\n%w", destination, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("unable to extract %s\n%w", file.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testWebAppLibs(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect  = NewWithT(t).Expect
+		testDir string
+		warPath string
+	)
+
+	it.Before(func() {
+		var err error
+		testDir, err = os.MkdirTemp("", "weblibs")
+		Expect(err).NotTo(HaveOccurred())
+
+		warPath = filepath.Join(testDir, "app.war")
+		Expect(os.WriteFile(warPath, newZip(map[string][]byte{
+			"index.html":                      []byte("<html/>"),
+			"WEB-INF/classes/Main.class":      []byte("class"),
+			"WEB-INF/lib/commons-lang3.jar":   []byte("lang"),
+			"WEB-INF/lib/alpha.jar":           []byte("alpha"),
+			"WEB-INF/lib/nested/ignored.jar":  []byte("nested"),
+			"WEB-INF/lib/README.txt":          []byte("readme"),
+			"META-INF/lib/not-a-web-lib.jar":  []byte("meta"),
+			"WEB-INF/lib-other/not-a-lib.jar": []byte("other"),
+		}), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(testDir)).To(Succeed())
+	})
+
+	it("returns the jars in WEB-INF/lib with their digests", func() {
+		libs, err := util.GetWebAppLibs(warPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(libs).To(Equal([]util.WebAppLib{
+			{Entry: "WEB-INF/lib/alpha.jar", SHA256: "8ed3f6ad685b959ead7022518e1af76cd816f8e8ec7ccdda1ed4018e8f2223f8"},
+			{Entry: "WEB-INF/lib/commons-lang3.jar", SHA256: "5cf7d7ec1cb5c221981f277051f7f82a82e155b53c76ab9b6f75fde9641f6fa0"},
+		}))
+		Expect(libs[1].Name()).To(Equal("commons-lang3.jar"))
+	})
+
+	it("returns no libraries for invalid archives", func() {
+		Expect(os.WriteFile(warPath, []byte("not a zip"), 0644)).To(Succeed())
+
+		libs, err := util.GetWebAppLibs(warPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(libs).To(BeEmpty())
+	})
+
+	it("extracts the libraries", func() {
+		libs, err := util.GetWebAppLibs(warPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		destination := filepath.Join(testDir, "libs")
+		Expect(util.ExtractWebAppLibs(warPath, libs, destination)).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(destination, "alpha.jar"))).To(Equal([]byte("alpha")))
+		Expect(os.ReadFile(filepath.Join(destination, "commons-lang3.jar"))).To(Equal([]byte("lang")))
+		entries, err := os.ReadDir(destination)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+	})
+}
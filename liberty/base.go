@@ -24,8 +24,10 @@ import (
 	"github.com/paketo-buildpacks/libpak/crush"
 	"github.com/paketo-buildpacks/libpak/sherpa"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -36,25 +38,62 @@ import (
 	"github.com/paketo-buildpacks/libpak/bard"
 )
 
+const springBootAppType = "spring"
+
+// Application deploy modes supported by BP_LIBERTY_APP_DEPLOY_MODE
+const (
+	expandedDeployMode = "expanded"
+	archiveDeployMode  = "archive"
+	dropinsDeployMode  = "dropins"
+)
+
 type Base struct {
 	ApplicationPath       string
 	BuildpackPath         string
 	LayerContributor      libpak.LayerContributor
 	Logger                bard.Logger
 	ServerName            string
+	ServerNames           []string
 	Features              []string
 	ContextRoot           string
+	ModuleContextRoots    map[string]string
+	AppDeployMode         string
 	UserFeatureDescriptor *FeatureDescriptor
 	LibertyBinding        libcnb.Binding
 	JVM                   string
+
+	// SharedLibsPath is the workspace directory holding jars that are shared with the application as a Liberty library
+	SharedLibsPath string
+
+	// DebugPort is the port that the `debug` process listens on
+	DebugPort string
+
+	// WebAppLibsPath is the path of the layer holding the `WEB-INF/lib` libraries of a compiled web archive. If set, the
+	// libraries are linked to it rather than extracted with the application.
+	WebAppLibsPath string
+	WebAppLibs     []util.WebAppLib
+
+	// SpringBootLibCache is the path of the Liberty library index cache holding the libraries of a Spring Boot
+	// application. If set, the application is deployed as a thin Spring Boot application.
+	SpringBootLibCache string
+}
+
+// ApplicationOptions are the settings that control how the application is deployed to the server. They are copied to
+// the fields of Base with the same name, except DeployMode which is copied to AppDeployMode.
+type ApplicationOptions struct {
+	ContextRoot        string
+	ModuleContextRoots map[string]string
+	DeployMode         string
+	SharedLibsPath     string
+	DebugPort          string
 }
 
 func NewBase(
 	appPath string,
 	buildpackPath string,
-	serverName string,
+	serverNames []string,
 	features []string,
-	contextRoot string,
+	options ApplicationOptions,
 	userFeatureDescriptor *FeatureDescriptor,
 	libertyBinding libcnb.Binding,
 	logger bard.Logger,
@@ -70,11 +109,15 @@ func NewBase(
 	}
 
 	expectedMetadata := map[string]interface{}{
-		"serverName":   serverName,
-		"features":     features,
-		"contextRoot":  contextRoot,
-		"userFeatures": enabledUserFeatures,
-		"workspaceSum": workspaceSum,
+		"serverNames":        serverNames,
+		"features":           features,
+		"contextRoot":        options.ContextRoot,
+		"moduleContextRoots": options.ModuleContextRoots,
+		"appDeployMode":      options.DeployMode,
+		"sharedLibs":         options.SharedLibsPath,
+		"debugPort":          options.DebugPort,
+		"userFeatures":       enabledUserFeatures,
+		"workspaceSum":       workspaceSum,
 	}
 
 	// Add checksum for /templates if provided
@@ -98,9 +141,14 @@ func NewBase(
 		ApplicationPath:       appPath,
 		BuildpackPath:         buildpackPath,
 		LayerContributor:      contributor,
-		ServerName:            serverName,
+		ServerName:            serverNames[0],
+		ServerNames:           serverNames,
 		Features:              features,
-		ContextRoot:           contextRoot,
+		ContextRoot:           options.ContextRoot,
+		ModuleContextRoots:    options.ModuleContextRoots,
+		AppDeployMode:         options.DeployMode,
+		SharedLibsPath:        options.SharedLibsPath,
+		DebugPort:             options.DebugPort,
 		UserFeatureDescriptor: userFeatureDescriptor,
 		LibertyBinding:        libertyBinding,
 		Logger:                logger,
@@ -111,17 +159,35 @@ func NewBase(
 func (b Base) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 	b.LayerContributor.Logger = b.Logger
 
-	return b.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+	layer, err := b.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
 		err := b.contribute(layer)
 		if err != nil {
 			return libcnb.Layer{}, fmt.Errorf("unable to contribute base\n%w", err)
 		}
 		return layer, nil
 	})
+	if err != nil {
+		return libcnb.Layer{}, err
+	}
+
+	// The shared library jars are loaded from this layer, so they are removed from the application on every build
+	// rather than shipping them twice and serving them as static content of an expanded web application
+	if err := b.removeSharedLibs(); err != nil {
+		return libcnb.Layer{}, err
+	}
+	return layer, nil
 }
 
 func (b Base) contribute(layer libcnb.Layer) error {
 	layer.LaunchEnvironment.Default("BPI_LIBERTY_SERVER_NAME", b.ServerName)
+	layer.LaunchEnvironment.Default("BPI_LIBERTY_SERVER_NAMES", strings.Join(b.ServerNames, " "))
+
+	// The debug process listens on all interfaces as it is used from outside the container
+	layer.LaunchEnvironment.ProcessDefault(debugProcessType, "BPI_LIBERTY_DEBUG_PROCESS", "true")
+	if b.DebugPort != "" {
+		layer.LaunchEnvironment.ProcessDefault(debugProcessType, "WLP_DEBUG_ADDRESS", b.DebugPort)
+		layer.LaunchEnvironment.ProcessDefault(debugProcessType, "WLP_DEBUG_REMOTE", "y")
+	}
 
 	// OpenJ9 only:
 	// Enable verbose GC logging by default as strongly recommended by Liberty support. It is low overhead and helps
@@ -186,6 +252,10 @@ func (b Base) contribute(layer libcnb.Layer) error {
 		return fmt.Errorf("unable to contribute user features\n%w", err)
 	}
 
+	if err := b.contributeSharedLibs(layer, serverPath); err != nil {
+		return fmt.Errorf("unable to contribute shared libraries\n%w", err)
+	}
+
 	configPath := filepath.Join(serverPath, "server.xml")
 	config, err := server.ReadServerConfig(configPath)
 	if err != nil {
@@ -262,6 +332,10 @@ func (b Base) contributeConfig(serverPath string) error {
 }
 
 func (b Base) contributeApp(layer libcnb.Layer, config server.Config) error {
+	if b.SpringBootLibCache != "" {
+		return b.contributeSpringBootApp(layer, config)
+	}
+
 	// Determine app path
 	var appPath string
 	if appPaths, err := util.GetApps(b.ApplicationPath); err != nil {
@@ -276,20 +350,34 @@ func (b Base) contributeApp(layer libcnb.Layer, config server.Config) error {
 
 	serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", b.ServerName)
 
+	isDir, err := sherpa.DirExists(appPath)
+	if err != nil {
+		return fmt.Errorf("unable to check if app path is a directory\n%w", err)
+	}
+
+	if b.AppDeployMode == dropinsDeployMode {
+		return b.contributeDropinsApp(serverPath, appPath, isDir)
+	}
+
 	linkPath := filepath.Join(serverPath, "apps", "app")
 	if err := os.RemoveAll(linkPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return fmt.Errorf("unable to remove app\n%w", err)
 	}
 
 	// Expand app if needed
-	isDir, err := sherpa.DirExists(appPath)
-	if err != nil {
-		return fmt.Errorf("unable to check if app path is a directory\n%w", err)
-	}
 	if isDir {
 		if err := os.Symlink(appPath, linkPath); err != nil {
 			return fmt.Errorf("unable to symlink application to '%s'\n%w", linkPath, err)
 		}
+	} else if b.AppDeployMode == archiveDeployMode {
+		// Keep the compiled artifact intact in the workspace and let Liberty expand it when the server starts
+		linkPath = filepath.Join(serverPath, "apps", "app"+filepath.Ext(appPath))
+		if err := util.DeleteAndLinkPath(appPath, linkPath); err != nil {
+			return fmt.Errorf("unable to symlink compiled artifact to '%s'\n%w", linkPath, err)
+		}
+		if err := b.contributeAutoExpandConfig(serverPath); err != nil {
+			return fmt.Errorf("unable to contribute auto expand config\n%w", err)
+		}
 	} else {
 		compiledArtifact, err := os.Open(appPath)
 		if err != nil {
@@ -298,22 +386,185 @@ func (b Base) contributeApp(layer libcnb.Layer, config server.Config) error {
 		if err := crush.Extract(compiledArtifact, linkPath, 0); err != nil {
 			return fmt.Errorf("unable to extract compiled artifact\n%w", err)
 		}
+		if err := b.linkWebAppLibs(linkPath); err != nil {
+			return fmt.Errorf("unable to link web application libraries\n%w", err)
+		}
 		if err := os.Remove(appPath); err != nil {
 			return fmt.Errorf("unable to remove compiled artifact\n%w", err)
 		}
 	}
 
-	appType := "war"
-	if _, err := os.Stat(filepath.Join(appPath, "META-INF", "application.xml")); err == nil {
-		appType = "ear"
+	// Check the contributed app as compiled enterprise archives have only been expanded to the link path
+	isEAR, err := util.IsEnterpriseApplication(linkPath)
+	if err != nil {
+		return fmt.Errorf("unable to check if app is an enterprise application\n%w", err)
+	}
+	if !isEAR {
+		if len(b.ModuleContextRoots) > 0 {
+			b.Logger.Info(color.YellowString("Warning: BP_LIBERTY_MODULE_CONTEXT_ROOTS is ignored as the application is not an enterprise application"))
+		}
+		if err := b.createAppConfig(serverPath, linkPath, b.ContextRoot, "war", nil, config); err != nil {
+			return fmt.Errorf("unable to create app config\n%w", err)
+		}
+		return nil
+	}
+
+	webExtensions, err := b.getWebExtensions(linkPath)
+	if err != nil {
+		return fmt.Errorf("unable to configure web modules\n%w", err)
+	}
+	if err := b.createAppConfig(serverPath, linkPath, "", "ear", webExtensions, config); err != nil {
+		return fmt.Errorf("unable to create app config\n%w", err)
+	}
+	return nil
+}
+
+// linkWebAppLibs replaces the libraries extracted with the application with links to the web application libraries
+// layer, so that the libraries are only stored once.
+func (b Base) linkWebAppLibs(appPath string) error {
+	if b.WebAppLibsPath == "" {
+		return nil
+	}
+
+	for _, lib := range b.WebAppLibs {
+		if err := util.DeleteAndLinkPath(filepath.Join(b.WebAppLibsPath, lib.Name()), filepath.Join(appPath, filepath.FromSlash(lib.Entry))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contributeDropinsApp links the application into the dropins directory, where Liberty deploys it using the
+// application's file name as its context root. No application config is generated.
+func (b Base) contributeDropinsApp(serverPath string, appPath string, isDir bool) error {
+	name := filepath.Base(appPath)
+	if isDir {
+		// Liberty uses the extension to determine the type of applications in the dropins directory
+		isEAR, err := util.IsEnterpriseApplication(appPath)
+		if err != nil {
+			return fmt.Errorf("unable to check if app is an enterprise application\n%w", err)
+		}
+		name = "app.war"
+		if isEAR {
+			name = "app.ear"
+		}
+	}
+
+	if b.ContextRoot != "" || len(b.ModuleContextRoots) > 0 {
+		b.Logger.Info(color.YellowString("Warning: BP_LIBERTY_CONTEXT_ROOT and BP_LIBERTY_MODULE_CONTEXT_ROOTS are ignored for applications deployed to dropins"))
+	}
+	if b.SharedLibsPath != "" {
+		b.Logger.Info(color.YellowString("Warning: The shared library '%s' is not referenced by applications deployed to dropins; reference it from server.xml instead", b.sharedLibraryId()))
+	}
+
+	dropinsPath := filepath.Join(serverPath, "dropins")
+	if err := os.MkdirAll(dropinsPath, 0755); err != nil {
+		return fmt.Errorf("unable to create dropins directory\n%w", err)
+	}
+
+	linkPath := filepath.Join(dropinsPath, name)
+	b.Logger.Bodyf("Deploying application to dropins/%s", name)
+	if err := util.DeleteAndLinkPath(appPath, linkPath); err != nil {
+		return fmt.Errorf("unable to symlink application to '%s'\n%w", linkPath, err)
+	}
+	return nil
+}
+
+// contributeAutoExpandConfig makes sure Liberty expands archived applications, even if the server.xml provided with
+// the application does not enable it.
+func (b Base) contributeAutoExpandConfig(serverPath string) error {
+	configPath := filepath.Join(serverPath, "configDropins", "defaults", "auto-expand.xml")
+	config := `<?xml version="1.0" encoding="UTF-8"?>
+<server>
+  <applicationManager autoExpand="true"/>
+</server>
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		return fmt.Errorf("unable to write file '%s'\n%w", configPath, err)
+	}
+	return nil
+}
+
+// getWebExtensions returns the context root overrides for the web modules of the enterprise application. The context
+// root of an application with a single web module can also be set with BP_LIBERTY_CONTEXT_ROOT.
+func (b Base) getWebExtensions(appPath string) ([]server.WebExtension, error) {
+	modules, err := util.GetWebModules(appPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, module := range modules {
+		b.Logger.Bodyf("Found web module '%s' with context root '%s'", module.Name(), module.ContextRoot)
+		names = append(names, module.Name())
+	}
+
+	contextRoots := make(map[string]string, len(b.ModuleContextRoots))
+	for name, contextRoot := range b.ModuleContextRoots {
+		contextRoots[name] = contextRoot
+	}
+	if b.ContextRoot != "" {
+		if len(modules) == 1 {
+			if _, found := contextRoots[names[0]]; !found {
+				contextRoots[names[0]] = b.ContextRoot
+			}
+		} else {
+			b.Logger.Info(color.YellowString("Warning: BP_LIBERTY_CONTEXT_ROOT is ignored as the enterprise application has %d web modules; use BP_LIBERTY_MODULE_CONTEXT_ROOTS instead", len(modules)))
+		}
+	}
+
+	var webExtensions []server.WebExtension
+	for _, name := range slices.Sorted(maps.Keys(contextRoots)) {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("unable to set context root for unknown web module '%s'; available web modules: %s", name, strings.Join(names, ", "))
+		}
+		b.Logger.Bodyf("Setting context root of web module '%s' to '%s'", name, contextRoots[name])
+		webExtensions = append(webExtensions, server.WebExtension{ModuleName: name, ContextRoot: contextRoots[name]})
+	}
+	return webExtensions, nil
+}
+
+// contributeSpringBootApp deploys the expanded Spring Boot application as a thin application. Its libraries are
+// loaded from the library index cache, which is linked to the shared resources directory where Liberty looks for it.
+func (b Base) contributeSpringBootApp(layer libcnb.Layer, config server.Config) error {
+	serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", b.ServerName)
+
+	libs, err := util.GetSpringBootLibs(b.ApplicationPath)
+	if err != nil {
+		return fmt.Errorf("unable to get Spring Boot libraries\n%w", err)
 	}
-	if err := b.createAppConfig(serverPath, linkPath, b.ContextRoot, appType, config); err != nil {
+
+	thinAppPath := filepath.Join(serverPath, "apps", "app.jar")
+	if err := util.WriteSpringBootThinApp(b.ApplicationPath, libs, thinAppPath); err != nil {
+		return fmt.Errorf("unable to create thin Spring Boot application\n%w", err)
+	}
+
+	sharedResourcesPath := filepath.Join(layer.Path, "wlp", "usr", "shared", "resources")
+	if err := os.MkdirAll(sharedResourcesPath, 0755); err != nil {
+		return fmt.Errorf("unable to create shared resources directory\n%w", err)
+	}
+	linkPath := filepath.Join(sharedResourcesPath, "lib.index.cache")
+	if err := os.RemoveAll(linkPath); err != nil {
+		return fmt.Errorf("unable to remove library index cache\n%w", err)
+	}
+	if err := os.Symlink(b.SpringBootLibCache, linkPath); err != nil {
+		return fmt.Errorf("unable to symlink library index cache to '%s'\n%w", linkPath, err)
+	}
+
+	if err := b.createAppConfig(serverPath, thinAppPath, b.ContextRoot, springBootAppType, nil, config); err != nil {
 		return fmt.Errorf("unable to create app config\n%w", err)
 	}
 	return nil
 }
 
-func (b Base) createAppConfig(serverPath string, appPath string, contextRoot string, appType string, config server.Config) error {
+func (b Base) createAppConfig(
+	serverPath string,
+	appPath string,
+	contextRoot string,
+	appType string,
+	webExtensions []server.WebExtension,
+	config server.Config,
+) error {
 	appConfigs := server.ProcessApplicationConfigs(config)
 
 	appIds := appConfigs.Ids()
@@ -337,6 +588,11 @@ func (b Base) createAppConfig(serverPath string, appPath string, contextRoot str
 			Type:        appType,
 			AppElement:  "application",
 		}
+		if appType == springBootAppType {
+			appConfig.AppElement = "springBootApplication"
+		} else if appType == "ear" {
+			appConfig.AppElement = "enterpriseApplication"
+		}
 	}
 
 	if appConfig.Id == "" {
@@ -363,12 +619,22 @@ func (b Base) createAppConfig(serverPath string, appPath string, contextRoot str
 	if appConfig.Type == "" {
 		appConfig.Type = appType
 	}
+	appConfig.WebExtensions = server.MergeWebExtensions(appConfig.WebExtensions, webExtensions)
+	if b.SharedLibsPath != "" {
+		appConfig.AddCommonLibraryRef(b.sharedLibraryId())
+	}
 
-	templatePath, err := b.getConfigTemplate("app.tmpl")
+	templateName := "app.tmpl"
+	if appConfig.AppElement == "springBootApplication" {
+		templateName = "spring-boot-app.tmpl"
+	} else if appConfig.AppElement == "enterpriseApplication" || appConfig.Type == "ear" {
+		templateName = "enterprise-app.tmpl"
+	}
+	templateName, templatePath, err := b.getAppConfigTemplate(templateName)
 	if err != nil {
 		return fmt.Errorf("unable to get app config template\n%w", err)
 	}
-	t, err := template.New("app.tmpl").ParseFiles(templatePath)
+	t, err := template.New(templateName).ParseFiles(templatePath)
 	if err != nil {
 		return fmt.Errorf("unable to create app template\n%w", err)
 	}
@@ -387,6 +653,101 @@ func (b Base) createAppConfig(serverPath string, appPath string, contextRoot str
 	return nil
 }
 
+// contributeSharedLibs copies the shared library jars to the shared resources directory and configures a library for
+// them, which createAppConfig references from the application's classloader.
+func (b Base) contributeSharedLibs(layer libcnb.Layer, serverPath string) error {
+	if b.SharedLibsPath == "" {
+		return nil
+	}
+
+	jars, err := util.GetFiles(b.SharedLibsPath, "*.jar")
+	if err != nil {
+		return fmt.Errorf("unable to find shared library jars\n%w", err)
+	}
+
+	id := b.sharedLibraryId()
+	libPath := filepath.Join(layer.Path, "wlp", "usr", "shared", "resources", id)
+	b.Logger.Bodyf("Adding %d jars to shared library '%s'", len(jars), id)
+	for _, jar := range jars {
+		rel, err := filepath.Rel(b.SharedLibsPath, jar)
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(jar)
+		if err != nil {
+			return fmt.Errorf("unable to open %s\n%w", jar, err)
+		}
+		err = sherpa.CopyFile(in, filepath.Join(libPath, rel))
+		in.Close()
+		if err != nil {
+			return fmt.Errorf("unable to copy %s\n%w", jar, err)
+		}
+	}
+
+	configPath := filepath.Join(serverPath, "configDropins", "defaults", "shared-libs.xml")
+	config := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<server>
+  <library id="%s">
+    <fileset dir="${shared.resource.dir}/%s" includes="**/*.jar"/>
+  </library>
+</server>
+`, id, id)
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		return fmt.Errorf("unable to write file '%s'\n%w", configPath, err)
+	}
+	return nil
+}
+
+// removeSharedLibs removes the shared library jars from the application, along with the directories left empty
+func (b Base) removeSharedLibs() error {
+	if b.SharedLibsPath == "" {
+		return nil
+	}
+
+	jars, err := util.GetFiles(b.SharedLibsPath, "*.jar")
+	if err != nil {
+		return fmt.Errorf("unable to find shared library jars\n%w", err)
+	}
+	for _, jar := range jars {
+		if err := os.Remove(jar); err != nil {
+			return fmt.Errorf("unable to remove shared library %s from the application\n%w", jar, err)
+		}
+	}
+
+	// Walk the directories deepest first so that a parent is empty once its empty children are removed
+	var dirs []string
+	err = filepath.WalkDir(b.SharedLibsPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("unable to read shared library directory %s\n%w", b.SharedLibsPath, err)
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			return fmt.Errorf("unable to read shared library directory %s\n%w", dirs[i], err)
+		}
+		if len(entries) == 0 {
+			if err := os.Remove(dirs[i]); err != nil {
+				return fmt.Errorf("unable to remove shared library directory %s\n%w", dirs[i], err)
+			}
+		}
+	}
+	return nil
+}
+
+// sharedLibraryId returns the ID of the library holding the shared library jars, which is the name of their directory.
+// getSharedLibsPath only accepts directories whose name is a valid ID.
+func (b Base) sharedLibraryId() string {
+	return filepath.Base(b.SharedLibsPath)
+}
+
 func (b Base) contributeUserFeatures(layer libcnb.Layer) error {
 	if len(b.UserFeatureDescriptor.Features) <= 0 {
 		b.Logger.Debug("No user features found; skipping...")
@@ -424,25 +785,51 @@ func (Base) Name() string {
 }
 
 func (b Base) getConfigTemplate(template string) (string, error) {
+	if templatePath, ok, err := b.getProvidedConfigTemplate(template); err != nil || ok {
+		return templatePath, err
+	}
+
+	// Return the default config template
+	return filepath.Join(b.BuildpackPath, "templates", template), nil
+}
+
+// getAppConfigTemplate returns the name and path of the template for the app config. Enterprise and Spring Boot
+// applications have their own templates, but an app.tmpl provided without them is used for every application, as it
+// was before those templates were added.
+func (b Base) getAppConfigTemplate(template string) (string, string, error) {
+	if template != "app.tmpl" {
+		if templatePath, ok, err := b.getProvidedConfigTemplate(template); err != nil || ok {
+			return template, templatePath, err
+		}
+		if templatePath, ok, err := b.getProvidedConfigTemplate("app.tmpl"); err != nil || ok {
+			return "app.tmpl", templatePath, err
+		}
+	}
+
+	templatePath, err := b.getConfigTemplate(template)
+	return template, templatePath, err
+}
+
+// getProvidedConfigTemplate returns the path of the config template if it has been provided in /templates or in a
+// liberty binding
+func (b Base) getProvidedConfigTemplate(template string) (string, bool, error) {
 	// Check if the config template has been provided in /templates
 	templatesPath := filepath.Join("/", "templates", template)
 	exists, err := sherpa.FileExists(templatesPath)
 	if err != nil {
-		return "", fmt.Errorf("unable to check for template at %s\n%w", templatesPath, err)
+		return "", false, fmt.Errorf("unable to check for template at %s\n%w", templatesPath, err)
 	}
 	if exists {
-		return templatesPath, nil
+		return templatesPath, true, nil
 	}
 
 	// Check if the config template has been provided in a liberty binding
 	// TODO: Remove this in next major release
 	if bindingPath, ok := b.LibertyBinding.SecretFilePath(template); ok {
 		b.Logger.Info(color.YellowString("Warning: Providing config templates via binding is deprecated. Mount the config templates to /templates instead."))
-		return bindingPath, nil
+		return bindingPath, true, nil
 	}
-
-	// Return the default config template
-	return filepath.Join(b.BuildpackPath, "templates", template), nil
+	return "", false, nil
 }
 
 func getTemplatesChecksum() (string, error) {
@@ -17,6 +17,8 @@
 package liberty_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -26,6 +28,7 @@ import (
 	"github.com/paketo-buildpacks/libpak/sherpa"
 
 	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/liberty/internal/util"
 	"github.com/paketo-buildpacks/liberty/liberty"
 	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/sclevine/spec"
@@ -91,9 +94,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -120,13 +123,65 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		Expect(wlpUserDir).To(Equal(filepath.Join(layer.Path, "wlp", "usr")))
 	})
 
+	it("configures the debug process", func() {
+		Expect(os.Mkdir(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+		base := liberty.NewBase(
+			ctx.Application.Path,
+			ctx.Buildpack.Path,
+			[]string{"defaultServer"},
+			[]string{"jsp-2.3"},
+			liberty.ApplicationOptions{
+				DebugPort: "8787",
+			},
+			&liberty.FeatureDescriptor{},
+			libcnb.Binding{},
+			bard.NewLogger(io.Discard),
+			"OpenJDK",
+		)
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = base.Contribute(layer)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(layer.LaunchEnvironment["debug/WLP_DEBUG_ADDRESS.default"]).To(Equal("8787"))
+		Expect(layer.LaunchEnvironment["debug/WLP_DEBUG_REMOTE.default"]).To(Equal("y"))
+		Expect(layer.LaunchEnvironment["debug/BPI_LIBERTY_DEBUG_PROCESS.default"]).To(Equal("true"))
+		Expect(layer.LaunchEnvironment).NotTo(HaveKey("WLP_DEBUG_ADDRESS.default"))
+	})
+
+	it("exports the names of the servers", func() {
+		Expect(os.Mkdir(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+		base := liberty.NewBase(
+			ctx.Application.Path,
+			ctx.Buildpack.Path,
+			[]string{"defaultServer", "testServer"},
+			[]string{"jsp-2.3"},
+			liberty.ApplicationOptions{},
+			&liberty.FeatureDescriptor{},
+			libcnb.Binding{},
+			bard.NewLogger(io.Discard),
+			"OpenJDK",
+		)
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = base.Contribute(layer)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(layer.LaunchEnvironment["BPI_LIBERTY_SERVER_NAME.default"]).To(Equal("defaultServer"))
+		Expect(layer.LaunchEnvironment["BPI_LIBERTY_SERVER_NAMES.default"]).To(Equal("defaultServer testServer"))
+	})
+
 	it("contributes a default server.xml", func() {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -158,9 +213,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jaxrs-2.1", "cdi-2.0"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -202,9 +257,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -233,6 +288,82 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		}
 	})
 
+	it("links the libraries of the compiled artifact to the web application libraries layer", func() {
+		buf := &bytes.Buffer{}
+		writer := zip.NewWriter(buf)
+		for name, content := range map[string]string{"index.html": "<html/>", "WEB-INF/lib/commons-lang3.jar": "lang"} {
+			w, err := writer.Create(name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(writer.Close()).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "test.war"), buf.Bytes(), 0644)).To(Succeed())
+
+		libsLayer, err := ctx.Layers.Layer("web-app-libs")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(libsLayer.Path, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(libsLayer.Path, "commons-lang3.jar"), []byte("lang"), 0644)).To(Succeed())
+
+		base := liberty.NewBase(
+			ctx.Application.Path,
+			ctx.Buildpack.Path,
+			[]string{"defaultServer"},
+			[]string{"jsp-2.3"},
+			liberty.ApplicationOptions{},
+			&liberty.FeatureDescriptor{},
+			libcnb.Binding{},
+			bard.NewLogger(io.Discard),
+			"OpenJDK",
+		)
+		base.WebAppLibsPath = libsLayer.Path
+		base.WebAppLibs = []util.WebAppLib{{Entry: "WEB-INF/lib/commons-lang3.jar"}}
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = base.Contribute(layer)
+		Expect(err).ToNot(HaveOccurred())
+
+		appPath := filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer", "apps", "app")
+		Expect(filepath.Join(appPath, "index.html")).To(BeARegularFile())
+		resolved, err := filepath.EvalSymlinks(filepath.Join(appPath, "WEB-INF", "lib", "commons-lang3.jar"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(Equal(filepath.Join(libsLayer.Path, "commons-lang3.jar")))
+	})
+
+	it("deploys the compiled artifact to dropins without app config", func() {
+		file, err := os.Open(filepath.Join("testdata", "test.war"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sherpa.CopyFile(file, filepath.Join(ctx.Application.Path, "test.war"))).To(Succeed())
+
+		base := liberty.NewBase(
+			ctx.Application.Path,
+			ctx.Buildpack.Path,
+			[]string{"defaultServer"},
+			[]string{"jsp-2.3"},
+			liberty.ApplicationOptions{
+				DeployMode: "dropins",
+			},
+			&liberty.FeatureDescriptor{},
+			libcnb.Binding{},
+			bard.NewLogger(io.Discard),
+			"OpenJDK",
+		)
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = base.Contribute(layer)
+		Expect(err).ToNot(HaveOccurred())
+
+		serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer")
+		resolved, err := filepath.EvalSymlinks(filepath.Join(serverPath, "dropins", "test.war"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resolved).To(Equal(filepath.Join(ctx.Application.Path, "test.war")))
+		Expect(filepath.Join(ctx.Application.Path, "test.war")).To(BeARegularFile())
+		Expect(filepath.Join(serverPath, "apps", "app")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(serverPath, "configDropins", "overrides", "app.xml")).ToNot(BeAnExistingFile())
+	})
+
 	it("sets the WLP_USER_DIR to packaged server's wlp directory", func() {
 		// Create packaged server
 		serverSourcePath := filepath.Join(ctx.Application.Path, "wlp", "usr", "servers", "defaultServer")
@@ -242,9 +373,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -272,9 +403,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -302,9 +433,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"testServer",
+			[]string{"testServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -357,9 +488,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			userFeatureDescriptor,
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -380,9 +511,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -400,9 +531,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 		base := liberty.NewBase(
 			ctx.Application.Path,
 			ctx.Buildpack.Path,
-			"defaultServer",
+			[]string{"defaultServer"},
 			[]string{"jsp-2.3"},
-			"",
+			liberty.ApplicationOptions{},
 			&liberty.FeatureDescriptor{},
 			libcnb.Binding{},
 			bard.NewLogger(os.Stdout),
@@ -430,9 +561,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 			base := liberty.NewBase(
 				ctx.Application.Path,
 				ctx.Buildpack.Path,
-				"defaultServer",
+				[]string{"defaultServer"},
 				[]string{"jsp-2.3"},
-				"",
+				liberty.ApplicationOptions{},
 				&liberty.FeatureDescriptor{},
 				libcnb.Binding{},
 				bard.NewLogger(os.Stdout),
@@ -454,6 +585,198 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 			Expect(string(bytes)).To(Equal(appXML))
 		})
 
+		when("contributing an enterprise application", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "templates", "enterprise-app.tmpl"),
+					[]byte(`<server><{{ .AppElement }} id="{{ .Id }}" location="{{ .Location }}">{{ range .WebExtensions }}<web-ext moduleName="{{ .ModuleName }}" context-root="{{ .ContextRoot }}"/>{{ end }}</{{ .AppElement }}></server>`), 0644)).To(Succeed())
+
+				buf := &bytes.Buffer{}
+				writer := zip.NewWriter(buf)
+				w, err := writer.Create("META-INF/application.xml")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = w.Write([]byte(`<application>
+  <module><web><web-uri>shop.war</web-uri><context-root>/shop</context-root></web></module>
+  <module><web><web-uri>admin.war</web-uri><context-root>/admin</context-root></web></module>
+</application>`))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(writer.Close()).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "test.ear"), buf.Bytes(), 0644)).To(Succeed())
+			})
+
+			it("detects a compiled enterprise archive and overrides module context roots", func() {
+				base := liberty.NewBase(
+					ctx.Application.Path,
+					ctx.Buildpack.Path,
+					[]string{"defaultServer"},
+					[]string{"jakartaee-10.0"},
+					liberty.ApplicationOptions{
+						ModuleContextRoots: map[string]string{"admin": "/console"},
+					},
+					&liberty.FeatureDescriptor{},
+					libcnb.Binding{},
+					bard.NewLogger(io.Discard),
+					"OpenJDK",
+				)
+				layer, err := ctx.Layers.Layer("test-layer")
+				Expect(err).ToNot(HaveOccurred())
+				layer, err = base.Contribute(layer)
+				Expect(err).ToNot(HaveOccurred())
+
+				serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer")
+				appXML, err := os.ReadFile(filepath.Join(serverPath, "configDropins", "overrides", "app.xml"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(appXML)).To(Equal(fmt.Sprintf(
+					`<server><enterpriseApplication id="app" location="%s"><web-ext moduleName="admin" context-root="/console"/></enterpriseApplication></server>`,
+					filepath.Join(serverPath, "apps", "app"))))
+			})
+
+			it("uses a provided app.tmpl when no enterprise-app.tmpl is provided", func() {
+				bindingPath := t.TempDir()
+				Expect(os.WriteFile(filepath.Join(bindingPath, "app.tmpl"),
+					[]byte(`<server><{{ .AppElement }} id="{{ .Id }}" location="{{ .Location }}" custom="true"/></server>`), 0644)).To(Succeed())
+
+				base := liberty.NewBase(
+					ctx.Application.Path,
+					ctx.Buildpack.Path,
+					[]string{"defaultServer"},
+					[]string{"jakartaee-10.0"},
+					liberty.ApplicationOptions{},
+					&liberty.FeatureDescriptor{},
+					libcnb.Binding{Name: "liberty", Type: "liberty", Path: bindingPath, Secret: map[string]string{"app.tmpl": ""}},
+					bard.NewLogger(io.Discard),
+					"OpenJDK",
+				)
+				layer, err := ctx.Layers.Layer("test-layer")
+				Expect(err).ToNot(HaveOccurred())
+				layer, err = base.Contribute(layer)
+				Expect(err).ToNot(HaveOccurred())
+
+				serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer")
+				appXML, err := os.ReadFile(filepath.Join(serverPath, "configDropins", "overrides", "app.xml"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(appXML)).To(Equal(fmt.Sprintf(
+					`<server><enterpriseApplication id="app" location="%s" custom="true"/></server>`,
+					filepath.Join(serverPath, "apps", "app"))))
+			})
+
+			it("keeps the enterprise archive intact when deploying it as an archive", func() {
+				base := liberty.NewBase(
+					ctx.Application.Path,
+					ctx.Buildpack.Path,
+					[]string{"defaultServer"},
+					[]string{"jakartaee-10.0"},
+					liberty.ApplicationOptions{
+						ModuleContextRoots: map[string]string{"shop": "/store"},
+						DeployMode:         "archive",
+					},
+					&liberty.FeatureDescriptor{},
+					libcnb.Binding{},
+					bard.NewLogger(io.Discard),
+					"OpenJDK",
+				)
+				layer, err := ctx.Layers.Layer("test-layer")
+				Expect(err).ToNot(HaveOccurred())
+				layer, err = base.Contribute(layer)
+				Expect(err).ToNot(HaveOccurred())
+
+				serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer")
+				resolved, err := filepath.EvalSymlinks(filepath.Join(serverPath, "apps", "app.ear"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resolved).To(Equal(filepath.Join(ctx.Application.Path, "test.ear")))
+				Expect(filepath.Join(ctx.Application.Path, "test.ear")).To(BeARegularFile())
+
+				appXML, err := os.ReadFile(filepath.Join(serverPath, "configDropins", "overrides", "app.xml"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(appXML)).To(Equal(fmt.Sprintf(
+					`<server><enterpriseApplication id="app" location="%s"><web-ext moduleName="shop" context-root="/store"/></enterpriseApplication></server>`,
+					filepath.Join(serverPath, "apps", "app.ear"))))
+				Expect(os.ReadFile(filepath.Join(serverPath, "configDropins", "defaults", "auto-expand.xml"))).
+					To(ContainSubstring(`<applicationManager autoExpand="true"/>`))
+			})
+
+			it("fails for unknown web modules", func() {
+				base := liberty.NewBase(
+					ctx.Application.Path,
+					ctx.Buildpack.Path,
+					[]string{"defaultServer"},
+					[]string{"jakartaee-10.0"},
+					liberty.ApplicationOptions{
+						ModuleContextRoots: map[string]string{"orders": "/orders"},
+					},
+					&liberty.FeatureDescriptor{},
+					libcnb.Binding{},
+					bard.NewLogger(io.Discard),
+					"OpenJDK",
+				)
+				layer, err := ctx.Layers.Layer("test-layer")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = base.Contribute(layer)
+				Expect(err).To(MatchError(ContainSubstring("unable to set context root for unknown web module 'orders'; available web modules: shop, admin")))
+			})
+
+			it("ignores the application context root if there are several web modules", func() {
+				base := liberty.NewBase(
+					ctx.Application.Path,
+					ctx.Buildpack.Path,
+					[]string{"defaultServer"},
+					[]string{"jakartaee-10.0"},
+					liberty.ApplicationOptions{
+						ContextRoot: "/store",
+					},
+					&liberty.FeatureDescriptor{},
+					libcnb.Binding{},
+					bard.NewLogger(io.Discard),
+					"OpenJDK",
+				)
+				layer, err := ctx.Layers.Layer("test-layer")
+				Expect(err).ToNot(HaveOccurred())
+				layer, err = base.Contribute(layer)
+				Expect(err).ToNot(HaveOccurred())
+
+				serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer")
+				appXML, err := os.ReadFile(filepath.Join(serverPath, "configDropins", "overrides", "app.xml"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(appXML)).NotTo(ContainSubstring("web-ext"))
+			})
+		})
+
+		it("contributes a thin Spring Boot application", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "templates", "spring-boot-app.tmpl"),
+				[]byte(`<server><springBootApplication id="{{ .Id }}" name="{{ .Name }}" location="{{ .Location }}"/></server>`), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+				[]byte("Spring-Boot-Version: 3.2.1\nSpring-Boot-Lib: BOOT-INF/lib/\n"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "BOOT-INF", "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "BOOT-INF", "lib", "spring-core.jar"), []byte("spring-core"), 0644)).To(Succeed())
+
+			base := liberty.NewBase(
+				ctx.Application.Path,
+				ctx.Buildpack.Path,
+				[]string{"defaultServer"},
+				[]string{"springBoot-3.0", "servlet-6.0"},
+				liberty.ApplicationOptions{},
+				&liberty.FeatureDescriptor{},
+				libcnb.Binding{},
+				bard.NewLogger(os.Stdout),
+				"OpenJDK",
+			)
+			base.SpringBootLibCache = filepath.Join(ctx.Layers.Path, "spring-boot-lib-cache")
+			layer, err := ctx.Layers.Layer("test-layer")
+			Expect(err).ToNot(HaveOccurred())
+			layer, err = base.Contribute(layer)
+			Expect(err).ToNot(HaveOccurred())
+
+			serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer")
+			thinAppPath := filepath.Join(serverPath, "apps", "app.jar")
+			Expect(thinAppPath).To(BeARegularFile())
+			Expect(os.Readlink(filepath.Join(layer.Path, "wlp", "usr", "shared", "resources", "lib.index.cache"))).
+				To(Equal(filepath.Join(ctx.Layers.Path, "spring-boot-lib-cache")))
+
+			appXML, err := os.ReadFile(filepath.Join(serverPath, "configDropins", "overrides", "app.xml"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(appXML)).To(Equal(fmt.Sprintf(`<server><springBootApplication id="app" name="app" location="%s"/></server>`, thinAppPath)))
+		})
+
 		it("contributes app.xml with existing app config from server.xml", func() {
 			serverXML := `<?xml version="1.0" encoding="UTF-8"?><server><webApplication id="myapp" name="myapp" context-root="/dev"/></server>`
 			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "server.xml"), []byte(serverXML), 0644)).To(Succeed())
@@ -461,9 +784,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 			base := liberty.NewBase(
 				ctx.Application.Path,
 				ctx.Buildpack.Path,
-				"defaultServer",
+				[]string{"defaultServer"},
 				[]string{"jsp-2.3"},
-				"",
+				liberty.ApplicationOptions{},
 				&liberty.FeatureDescriptor{},
 				libcnb.Binding{},
 				bard.NewLogger(os.Stdout),
@@ -492,9 +815,9 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 			base := liberty.NewBase(
 				ctx.Application.Path,
 				ctx.Buildpack.Path,
-				"defaultServer",
+				[]string{"defaultServer"},
 				[]string{"jsp-2.3"},
-				"",
+				liberty.ApplicationOptions{},
 				&liberty.FeatureDescriptor{},
 				libcnb.Binding{},
 				bard.NewLogger(os.Stdout),
@@ -520,9 +843,11 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 			base := liberty.NewBase(
 				ctx.Application.Path,
 				ctx.Buildpack.Path,
-				"defaultServer",
+				[]string{"defaultServer"},
 				[]string{"jsp-2.3"},
-				"/app",
+				liberty.ApplicationOptions{
+					ContextRoot: "/app",
+				},
 				&liberty.FeatureDescriptor{},
 				libcnb.Binding{},
 				bard.NewLogger(os.Stdout),
@@ -543,5 +868,55 @@ func testBase(t *testing.T, when spec.G, it spec.S) {
 				filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer", "apps", "app"))
 			Expect(string(bytes)).To(Equal(appXML))
 		})
+
+		it("shares the library jars with the application", func() {
+			file, err := os.Open(filepath.Join("..", "templates", "app.tmpl"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sherpa.CopyFile(file, filepath.Join(ctx.Buildpack.Path, "templates", "app.tmpl"))).To(Succeed())
+
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "lib", "vendor-sdk.jar"), []byte("sdk"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "lib", "README.txt"), []byte{}, 0644)).To(Succeed())
+
+			base := liberty.NewBase(
+				ctx.Application.Path,
+				ctx.Buildpack.Path,
+				[]string{"defaultServer"},
+				[]string{"jsp-2.3"},
+				liberty.ApplicationOptions{
+					SharedLibsPath: filepath.Join(ctx.Application.Path, "lib"),
+				},
+				&liberty.FeatureDescriptor{},
+				libcnb.Binding{},
+				bard.NewLogger(io.Discard),
+				"OpenJDK",
+			)
+			layer, err := ctx.Layers.Layer("test-layer")
+			Expect(err).ToNot(HaveOccurred())
+			layer, err = base.Contribute(layer)
+			Expect(err).ToNot(HaveOccurred())
+
+			sharedPath := filepath.Join(layer.Path, "wlp", "usr", "shared", "resources", "lib")
+			Expect(os.ReadFile(filepath.Join(sharedPath, "vendor-sdk.jar"))).To(Equal([]byte("sdk")))
+			Expect(filepath.Join(sharedPath, "README.txt")).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(ctx.Application.Path, "lib", "vendor-sdk.jar")).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(ctx.Application.Path, "lib", "README.txt")).To(BeAnExistingFile())
+
+			serverPath := filepath.Join(layer.Path, "wlp", "usr", "servers", "defaultServer")
+			Expect(os.ReadFile(filepath.Join(serverPath, "configDropins", "defaults", "shared-libs.xml"))).To(ContainSubstring(`<library id="lib">
+    <fileset dir="${shared.resource.dir}/lib" includes="**/*.jar"/>
+  </library>`))
+
+			appXML, err := os.ReadFile(filepath.Join(serverPath, "configDropins", "overrides", "app.xml"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(appXML)).To(Equal(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<server>
+  <application id="app" name="app" type="war" location="%s" context-root="/">
+    <classloader commonLibraryRef="lib"/>
+  </application>
+</server>
+`, filepath.Join(serverPath, "apps", "app"))))
+		})
 	})
 }
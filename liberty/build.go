@@ -18,6 +18,10 @@ package liberty
 
 import (
 	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -26,6 +30,7 @@ import (
 	"github.com/paketo-buildpacks/libpak/sherpa"
 
 	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
 	"github.com/paketo-buildpacks/liberty/internal/core"
 	"github.com/paketo-buildpacks/liberty/internal/server"
 	"github.com/paketo-buildpacks/libpak"
@@ -38,13 +43,44 @@ const (
 	openLibertyInstall          = "ol"
 	websphereLibertyInstall     = "wlp"
 	noneInstall                 = "none"
+	bundledInstall              = "bundled"
 	openLibertyStackRuntimeRoot = "/opt/ol"
 	webSphereLibertyRuntimeRoot = "/opt/ibm"
 	javaAppServerLiberty        = "liberty"
 	ifixesRoot                  = "/ifixes"
 	featuresRoot                = "/features"
+	kernelProfile               = "kernel"
+	fullProfile                 = "full"
+	autoProfile                 = "auto"
+	autoFeatures                = "auto"
+	debugProcessType            = "debug"
+	autoIterationsValue         = "auto"
+	defaultHTTPEndpoint         = "http://localhost:9080"
+
+	// minCDSJavaVersion is the first Java version whose HotSpot JVM supports -XX:ArchiveClassesAtExit
+	minCDSJavaVersion = 13
 )
 
+// serverActionProcesses are the process types that run the default server with another action of the `server`
+// command, for debugging and diagnosing the server
+var serverActionProcesses = []struct {
+	Type    string
+	Action  string
+	Options []string
+}{
+	{Type: debugProcessType, Action: "debug"},
+	{Type: "clean", Action: "run", Options: []string{"--clean"}},
+	{Type: "dump", Action: "dump"},
+	{Type: "javadump", Action: "javadump"},
+}
+
+// invalidProcessTypeChars matches the characters of a server name that are not allowed in a process type
+var invalidProcessTypeChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// validLibraryId matches the names of shared library directories that can be used as the ID of a Liberty library,
+// which must be a valid XML ID
+var validLibraryId = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]*$`)
+
 type Build struct {
 	Executor    effect.Executor
 	Logger      bard.Logger
@@ -74,12 +110,14 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		return result, nil
 	}
 
-	serverName, _ := cr.Resolve("BP_LIBERTY_SERVER_NAME")
-	serverBuildSrc := core.NewServerBuildSource(context.Application.Path, serverName, b.Logger)
+	requestedServers, _ := cr.Resolve("BP_LIBERTY_SERVER_NAME")
+	serverBuildSrc := core.NewServerBuildSource(context.Application.Path, requestedServers, b.Logger)
+	springBootBuildSrc := core.NewSpringBootBuildSource(context.Application.Path, appServer, b.Logger)
 	appBuildSrc := core.NewAppBuildSource(context.Application.Path, core.JavaAppServerLiberty, b.Logger)
 
 	buildSources := []core.BuildSource{
 		serverBuildSrc,
+		springBootBuildSrc,
 		appBuildSrc,
 	}
 
@@ -120,6 +158,13 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 
 	b.Logger.Title(context.Buildpack)
 
+	_, isServer := detectedBuildSrc.(core.ServerBuildSource)
+	if isServer {
+		if _, err := serverBuildSrc.ExtractPackage(); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to extract server package\n%w", err)
+		}
+	}
+
 	cr, err = libpak.NewConfigurationResolver(context.Buildpack, &b.Logger) // recreate so that config table is logged after the title
 	if err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to create configuration resolver\n%w", err)
@@ -136,17 +181,30 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 	}
 	dc.Logger = b.Logger
 
-	h := libpak.NewHelperLayerContributor(context.Buildpack, "linker")
+	h := libpak.NewHelperLayerContributor(context.Buildpack, "linker", "debug", "stack")
 	h.Logger = b.Logger
 	result.Layers = append(result.Layers, h)
 
-	if serverName == "" {
-		serverName, err = detectedBuildSrc.DefaultServerName()
+	serverNames := []string{serverBuildSrc.ServerName}
+	if isServer {
+		serverNames, err = serverBuildSrc.Servers()
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to get servers for '%s'\n%w", detectedBuildSrc.Name(), err)
+		}
+	} else if len(serverBuildSrc.ServerNames) > 1 || serverBuildSrc.IsAllServers() {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to build several servers for '%s'; "+
+			"BP_LIBERTY_SERVER_NAME may only select several servers when building a packaged server", detectedBuildSrc.Name())
+	} else if serverNames[0] == "" {
+		serverNames[0], err = detectedBuildSrc.DefaultServerName()
 		if err != nil {
 			return libcnb.BuildResult{},
 				fmt.Errorf("unable to get default server name for '%s'\n%w", detectedBuildSrc.Name(), err)
 		}
 	}
+	serverName := serverNames[0]
+	if len(serverNames) > 1 {
+		b.Logger.Bodyf("Building servers %s; %s is the default", strings.Join(serverNames, ", "), serverName)
+	}
 
 	if b.SBOMScanner == nil {
 		b.SBOMScanner = sbom.NewSyftCLISBOMScanner(context.Layers, effect.CommandExecutor{}, b.Logger)
@@ -156,99 +214,296 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 	profile, _ := cr.Resolve("BP_LIBERTY_PROFILE")
 	if profile == "" {
 		if installType == openLibertyInstall {
-			profile = "kernel"
+			profile = kernelProfile
 		} else if installType == websphereLibertyInstall {
-			profile = "kernel"
+			profile = kernelProfile
 		}
 	}
 
-	isValidProfile := true
-	if installType == openLibertyInstall {
-		isValidProfile = server.IsValidOpenLibertyProfile(profile)
-	} else if installType == websphereLibertyInstall {
-		isValidProfile = server.IsValidWebSphereLibertyProfile(profile)
+	// The none and bundled install types use a runtime provided outside of the buildpack, so profiles do not apply
+	externalRuntime := installType == noneInstall || installType == bundledInstall
+	isAutoProfile := profile == autoProfile
+	if isAutoProfile && externalRuntime {
+		profile = kernelProfile
+		isAutoProfile = false
+	}
+
+	profiles, err := server.NewProfileCatalog(context.Buildpack.Metadata, getDistributionType(installType))
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to read Liberty profiles\n%w", err)
 	}
-	if !isValidProfile {
-		return libcnb.BuildResult{}, fmt.Errorf("invalid profile '%s' for BP_INSTALL_TYPE '%s'", profile, installType)
+	if isAutoProfile {
+		b.Logger.Debug("Profile will be selected based on the required features")
+	} else if !externalRuntime && !profiles.IsValid(profile) {
+		return libcnb.BuildResult{}, fmt.Errorf("invalid profile '%s' for BP_LIBERTY_INSTALL_TYPE '%s'; available profiles: %s",
+			profile, installType, strings.Join(profiles.Names(), ", "))
 	}
 
 	version, _ := cr.Resolve("BP_LIBERTY_VERSION")
 	features, _ := cr.Resolve("BP_LIBERTY_FEATURES")
 	featureList := strings.Fields(features)
-	disableFeatureInstall := false
-	if profile == "full" {
-		disableFeatureInstall = true
-	}
-	if val, isSet := cr.Resolve("BP_LIBERTY_FEATURE_INSTALL_DISABLED"); isSet {
-		disableFeatureInstall = val == "true"
-	}
 	appPath, err := detectedBuildSrc.AppPath()
 	if err != nil {
 		return libcnb.BuildResult{}, err
 	}
-	featureList, err = server.GetFeatureList(profile, appPath, featureList)
+	// The application is only scanned when the result is needed, either to enable the discovered features or when the
+	// buildpack chooses the profile or the default features
+	isAutoFeatures := strings.TrimSpace(features) == autoFeatures
+	var appScan *util.ApplicationScan
+	if isAutoFeatures {
+		scan, err := util.ScanApplication(appPath)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to scan application\n%w", err)
+		}
+		appScan = &scan
+		featureList = server.DiscoverFeatures(scan)
+		if len(featureList) > 0 {
+			b.Logger.Bodyf("Enabling features discovered in the application: %s", strings.Join(featureList, ", "))
+		} else {
+			b.Logger.Info(color.YellowString("Warning: Unable to discover features in the application"))
+		}
+	} else if isAutoProfile {
+		appScan = b.scanApplication(appPath)
+	}
+	springBootSrc, isSpringBoot := detectedBuildSrc.(core.SpringBootBuildSource)
+	if isSpringBoot {
+		springBootFeatures, err := springBootSrc.Features()
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to determine Spring Boot features\n%w", err)
+		}
+		featureList = append(featureList, springBootFeatures...)
+	}
+	defaultFeaturesProfile := profile
+	if isAutoProfile {
+		defaultFeaturesProfile = kernelProfile
+	}
+	featureList, err = server.GetFeatureList(nil, appPath, featureList)
 	if err != nil {
 		return libcnb.BuildResult{}, err
 	}
+	usingDefaultFeatures := len(featureList) == 0
+	if usingDefaultFeatures {
+		if appScan == nil {
+			appScan = b.scanApplication(appPath)
+		}
+		featureList = b.getDefaultFeatures(profiles, defaultFeaturesProfile, *appScan)
+	}
+	// The features chosen by the buildpack may miss those the application uses, so the missing ones are suggested
+	if !isAutoFeatures && (isAutoProfile || usingDefaultFeatures) {
+		var missingFeatures []string
+		for _, feature := range server.DiscoverFeatures(*appScan) {
+			if !slices.Contains(featureList, feature) {
+				missingFeatures = append(missingFeatures, feature)
+			}
+		}
+		if len(missingFeatures) > 0 {
+			b.Logger.Bodyf("Discovered features in the application: %s; set BP_LIBERTY_FEATURES=auto to enable them",
+				strings.Join(missingFeatures, ", "))
+		}
+	}
 	userFeatureDescriptor, err := ReadFeatureDescriptor(featuresRoot, b.Logger)
 	if err != nil {
 		return libcnb.BuildResult{}, err
 	}
+
+	disableFeatureInstall := false
+	if isAutoProfile {
+		requiredFeatures := append([]string{}, featureList...)
+		for _, feature := range userFeatureDescriptor.Features {
+			requiredFeatures = append(requiredFeatures, feature.Dependencies...)
+		}
+		profile = b.selectProfile(profiles, installType, version, requiredFeatures, dr)
+		disableFeatureInstall = profile != kernelProfile
+	}
+	if profile == fullProfile {
+		disableFeatureInstall = true
+	}
+	if val, isSet := cr.Resolve("BP_LIBERTY_FEATURE_INSTALL_DISABLED"); isSet {
+		disableFeatureInstall = val == "true"
+	}
+
 	binding, _, err := bindings.ResolveOne(context.Platform.Bindings, bindings.OfType("liberty"))
 	if err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve liberty bindings\n%w", err)
 	}
-	jvmName, err := util.DetectJVMName(b.Executor)
+	jvm, err := util.DetectJVM(b.Executor)
 	if err != nil {
 		return libcnb.BuildResult{}, err
 	}
+	b.Logger.Debugf("Detected JVM: %s", jvm)
+	jvmName := jvm.Name()
+	if err := server.CheckJavaCompatibility(jvm.Version, "", featureList, b.Logger); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to use the features with the JVM\n%w", err)
+	}
 	contextRoot, _ := cr.Resolve("BP_LIBERTY_CONTEXT_ROOT")
+	rawModuleContextRoots, _ := cr.Resolve("BP_LIBERTY_MODULE_CONTEXT_ROOTS")
+	moduleContextRoots, err := parseModuleContextRoots(rawModuleContextRoots)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to parse BP_LIBERTY_MODULE_CONTEXT_ROOTS\n%w", err)
+	}
+	appDeployMode, _ := cr.Resolve("BP_LIBERTY_APP_DEPLOY_MODE")
+	if appDeployMode == "" {
+		appDeployMode = expandedDeployMode
+	}
+	if !slices.Contains([]string{expandedDeployMode, archiveDeployMode, dropinsDeployMode}, appDeployMode) {
+		return libcnb.BuildResult{}, fmt.Errorf("invalid BP_LIBERTY_APP_DEPLOY_MODE '%s'; expected one of: %s, %s, %s",
+			appDeployMode, expandedDeployMode, archiveDeployMode, dropinsDeployMode)
+	}
+	iFixPolicy, _ := cr.Resolve("BP_LIBERTY_IFIX_POLICY")
+	if iFixPolicy == "" {
+		iFixPolicy = server.IFixPolicySkip
+	}
+	if !slices.Contains([]string{server.IFixPolicySkip, server.IFixPolicyFail}, iFixPolicy) {
+		return libcnb.BuildResult{}, fmt.Errorf("invalid BP_LIBERTY_IFIX_POLICY '%s'; expected one of: %s, %s",
+			iFixPolicy, server.IFixPolicySkip, server.IFixPolicyFail)
+	}
+	iFixDescriptorPath := filepath.Join(context.Application.Path, "ifixes.toml")
+	if path, ok := binding.SecretFilePath("ifixes.toml"); ok {
+		iFixDescriptorPath = path
+	}
+	iFixDescriptor, err := ReadIFixDescriptor(iFixDescriptorPath, b.Logger)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to read iFix descriptor\n%w", err)
+	}
+	iFixes := iFixOptions{Policy: iFixPolicy, Descriptor: iFixDescriptor, Cache: dc}
+	debugPort, _ := cr.Resolve("BP_LIBERTY_DEBUG_PORT")
+	if port, err := strconv.Atoi(debugPort); debugPort != "" && (err != nil || port < 1 || port > 65535) {
+		return libcnb.BuildResult{}, fmt.Errorf("invalid BP_LIBERTY_DEBUG_PORT '%s'; expected a port number", debugPort)
+	}
+	var sharedLibsPath string
+	if !isServer {
+		sharedLibsPath, err = getSharedLibsPath(cr, context.Application.Path)
+		if err != nil {
+			return libcnb.BuildResult{}, err
+		}
+	}
 	base := NewBase(
 		context.Application.Path,
 		context.Buildpack.Path,
-		serverName,
+		serverNames,
 		featureList,
-		contextRoot,
+		ApplicationOptions{
+			ContextRoot:        contextRoot,
+			ModuleContextRoots: moduleContextRoots,
+			DeployMode:         appDeployMode,
+			SharedLibsPath:     sharedLibsPath,
+			DebugPort:          debugPort,
+		},
 		userFeatureDescriptor,
 		binding,
 		b.Logger,
 		jvmName,
 	)
-	result.Layers = append(result.Layers, base)
+	if !isServer && !isSpringBoot && appDeployMode == expandedDeployMode {
+		webAppLibs, err := b.getWebAppLibs(context.Application.Path)
+		if err != nil {
+			return libcnb.BuildResult{}, err
+		}
+		if len(webAppLibs.Libs) > 0 {
+			// The libraries are contributed before the base layer, which links to them when expanding the application
+			base.WebAppLibsPath = filepath.Join(context.Layers.Path, webAppLibs.Name())
+			base.WebAppLibs = webAppLibs.Libs
+			result.Layers = append(result.Layers, webAppLibs)
+		}
+	}
+	if isSpringBoot {
+		libs, err := util.GetSpringBootLibs(appPath)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to get Spring Boot libraries\n%w", err)
+		}
+		libCache := NewSpringBootLibCache(libs)
+		libCache.Logger = b.Logger
+		base.SpringBootLibCache = filepath.Join(context.Layers.Path, libCache.Name())
+		result.Layers = append(result.Layers, base, libCache)
+	} else {
+		result.Layers = append(result.Layers, base)
+	}
 
+	// runtimeLayer is the name of the layer the server runs from
+	var runtimeLayer string
 	if installType == openLibertyInstall || installType == websphereLibertyInstall {
-		sccOptions, err := getSharedClassOptions(cr, jvmName)
+		sccOptions, err := b.getSharedClassOptions(cr, jvm, context.Application.Path)
 		if err != nil {
 			return libcnb.BuildResult{}, fmt.Errorf("unable to get SCC options\n%w", err)
 		}
-		if err := b.buildDistributionRuntime(
+		if runtimeLayer, err = b.buildDistributionRuntime(
 			profile,
 			version,
 			installType,
-			serverName,
+			serverNames,
+			context.Layers.Path,
 			context.Application.Path,
 			disableFeatureInstall,
 			featureList,
 			detectedBuildSrc,
 			sccOptions,
+			iFixes,
+			jvm,
 			dr,
 			dc,
 			&result); err != nil {
 			return libcnb.BuildResult{}, err
 		}
+	} else if installType == bundledInstall {
+		sccOptions, err := b.getSharedClassOptions(cr, jvm, context.Application.Path)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to get SCC options\n%w", err)
+		}
+		if runtimeLayer, err = b.buildBundledRuntime(serverNames, detectedBuildSrc, sccOptions, iFixes, jvm, &result); err != nil {
+			return libcnb.BuildResult{}, err
+		}
 	} else if installType == noneInstall {
-		if err := b.buildStackRuntime(serverName, &result); err != nil {
+		if err := b.buildStackRuntime(serverNames, &result); err != nil {
 			return libcnb.BuildResult{}, err
 		}
 	} else {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to process install type: '%s'", installType)
 	}
 
+	if cr.ResolveBool("BP_LIBERTY_VERIFY") {
+		if installType == noneInstall {
+			b.Logger.Info(color.YellowString("Warning: BP_LIBERTY_VERIFY is ignored for BP_LIBERTY_INSTALL_TYPE '%s' as the runtime is only available at launch", noneInstall))
+		} else {
+			endpointURLs, err := parseEndpointURLs(cr, "BP_LIBERTY_VERIFY_URLS")
+			if err != nil {
+				return libcnb.BuildResult{}, err
+			}
+			// The server runs from the overlay layer of a distribution or from the bundled runtime layer, and is
+			// verified once every layer is in place
+			runtimePath := filepath.Join(context.Layers.Path, runtimeLayer)
+			verifier := NewVerifier(runtimePath, serverNames, endpointURLs, b.Executor)
+			verifier.Logger = b.Logger
+			result.Layers = append(result.Layers, verifier)
+		}
+	}
+
 	return result, nil
 }
 
-func getSharedClassOptions(cr libpak.ConfigurationResolver, jvmName string) (util.SharedClassCacheOptions, error) {
-	if jvmName != "OpenJ9" {
+func (b Build) getSharedClassOptions(cr libpak.ConfigurationResolver, jvm util.JVM, appPath string) (util.SharedClassCacheOptions, error) {
+	trainingURLs, err := parseEndpointURLs(cr, "BP_LIBERTY_SCC_TRAINING_URLS")
+	if err != nil {
+		return util.SharedClassCacheOptions{}, err
+	}
+	trainingScript, _ := cr.Resolve("BP_LIBERTY_SCC_TRAINING_SCRIPT")
+	if trainingScript != "" && !filepath.IsAbs(trainingScript) {
+		trainingScript = filepath.Join(appPath, trainingScript)
+	}
+
+	jvmName := jvm.Name()
+	if jvmName == "OpenJDK" {
+		enabled := !cr.ResolveBool("BP_LIBERTY_CDS_DISABLED")
+		if enabled && jvm.Version < minCDSJavaVersion {
+			b.Logger.Bodyf("Skipping the CDS archive as it requires Java %d or later, but the JVM is Java %d", minCDSJavaVersion, jvm.Version)
+			enabled = false
+		}
+		return util.SharedClassCacheOptions{
+			Enabled:        enabled,
+			CDS:            true,
+			TrainingURLs:   trainingURLs,
+			TrainingScript: trainingScript,
+		}, nil
+	} else if jvmName != "OpenJ9" {
 		return util.SharedClassCacheOptions{
 			Enabled: false,
 		}, nil
@@ -260,115 +515,449 @@ func getSharedClassOptions(cr libpak.ConfigurationResolver, jvmName string) (uti
 		return util.SharedClassCacheOptions{}, fmt.Errorf("unable to parse BP_LIBERTY_SCC_SIZE_MB\n%w", err)
 	}
 	resolvedNumIterations, _ := cr.Resolve("BP_LIBERTY_SCC_NUM_ITERATIONS")
-	numIterations, err := strconv.Atoi(resolvedNumIterations)
-	if err != nil {
-		return util.SharedClassCacheOptions{}, fmt.Errorf("unable to parse BP_LIBERTY_SCC_NUM_ITERATIONS\n%w", err)
+	autoIterations := resolvedNumIterations == autoIterationsValue
+	numIterations := 0
+	if !autoIterations {
+		numIterations, err = strconv.Atoi(resolvedNumIterations)
+		if err != nil {
+			return util.SharedClassCacheOptions{}, fmt.Errorf("unable to parse BP_LIBERTY_SCC_NUM_ITERATIONS\n%w", err)
+		}
 	}
 
 	return util.SharedClassCacheOptions{
-		Enabled:       !cr.ResolveBool("BP_LIBERTY_SCC_DISABLED"),
-		SizeMB:        size,
-		NumIterations: numIterations,
-		Trim:          !cr.ResolveBool("BP_LIBERTY_SCC_TRIM_SIZE_DISABLED"),
+		Enabled:        !cr.ResolveBool("BP_LIBERTY_SCC_DISABLED"),
+		SizeMB:         size,
+		NumIterations:  numIterations,
+		Trim:           !cr.ResolveBool("BP_LIBERTY_SCC_TRIM_SIZE_DISABLED"),
+		AutoIterations: autoIterations,
+		TrainingURLs:   trainingURLs,
+		TrainingScript: trainingScript,
 	}, nil
 }
 
+// parseEndpointURLs parses the space separated list of URLs in the configuration. Paths are requested from the default
+// HTTP endpoint of the server.
+func parseEndpointURLs(cr libpak.ConfigurationResolver, name string) ([]string, error) {
+	resolved, _ := cr.Resolve(name)
+
+	var endpointURLs []string
+	for _, value := range strings.Fields(resolved) {
+		if strings.HasPrefix(value, "/") {
+			value = defaultHTTPEndpoint + value
+		}
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid %s entry '%s'; expected a path or an http(s) URL", name, value)
+		}
+		endpointURLs = append(endpointURLs, value)
+	}
+	return endpointURLs, nil
+}
+
+// parseModuleContextRoots parses a space separated list of `<module>=<context-root>` pairs. The module may be given
+// with or without its `.war` extension.
+func parseModuleContextRoots(value string) (map[string]string, error) {
+	contextRoots := map[string]string{}
+	for _, pair := range strings.Fields(value) {
+		module, contextRoot, ok := strings.Cut(pair, "=")
+		if !ok || module == "" || contextRoot == "" {
+			return nil, fmt.Errorf("invalid module context root '%s'; expected <module>=<context-root>", pair)
+		}
+		contextRoots[strings.TrimSuffix(module, ".war")] = contextRoot
+	}
+	return contextRoots, nil
+}
+
+// getWebAppLibs returns the contributor for the `WEB-INF/lib` libraries of the compiled web archive in the workspace.
+// It has no libraries if the workspace does not hold a single compiled web archive.
+func (b Build) getWebAppLibs(appPath string) (WebAppLibs, error) {
+	apps, err := util.GetApps(appPath)
+	if err != nil {
+		return WebAppLibs{}, fmt.Errorf("unable to find applications in %s\n%w", appPath, err)
+	}
+	if len(apps) != 1 || filepath.Ext(apps[0]) != ".war" {
+		return WebAppLibs{}, nil
+	}
+	if isFile, err := sherpa.FileExists(apps[0]); err != nil {
+		return WebAppLibs{}, fmt.Errorf("unable to check %s\n%w", apps[0], err)
+	} else if !isFile {
+		return WebAppLibs{}, nil
+	}
+
+	libs, err := util.GetWebAppLibs(apps[0])
+	if err != nil {
+		return WebAppLibs{}, fmt.Errorf("unable to get web application libraries\n%w", err)
+	}
+
+	webAppLibs := NewWebAppLibs(apps[0], libs)
+	webAppLibs.Logger = b.Logger
+	return webAppLibs, nil
+}
+
+// getSharedLibsPath returns the workspace directory holding the jars to share with the application. The directory is
+// named by BP_LIBERTY_SHARED_LIBS, relative to the workspace, and defaults to `lib` if that exists. Returns the empty
+// string if there are no shared libraries. There is no default for an expanded enterprise application, where `lib` is
+// the library directory of the application itself and sharing its jars would load them twice. The directory must be
+// inside the workspace, and its name is the ID of the library.
+func getSharedLibsPath(cr libpak.ConfigurationResolver, appPath string) (string, error) {
+	dir, isSet := cr.Resolve("BP_LIBERTY_SHARED_LIBS")
+	if dir == "" {
+		if isEAR, err := sherpa.FileExists(filepath.Join(appPath, "META-INF", "application.xml")); err != nil {
+			return "", fmt.Errorf("unable to check application.xml\n%w", err)
+		} else if isEAR {
+			return "", nil
+		}
+		dir, isSet = "lib", false
+	}
+
+	if !isSharedLibsDirInWorkspace(appPath, dir) {
+		return "", fmt.Errorf("invalid BP_LIBERTY_SHARED_LIBS '%s'; expected a directory inside the workspace", dir)
+	}
+	path := filepath.Join(appPath, dir)
+	if exists, err := sherpa.DirExists(path); err != nil {
+		return "", fmt.Errorf("unable to check shared library directory\n%w", err)
+	} else if !exists {
+		if isSet {
+			return "", fmt.Errorf("unable to find shared library directory '%s' set by BP_LIBERTY_SHARED_LIBS", dir)
+		}
+		return "", nil
+	}
+
+	// The directory may be a symlink that leads out of the workspace
+	resolvedAppPath, err := filepath.EvalSymlinks(appPath)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve workspace directory\n%w", err)
+	}
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve shared library directory\n%w", err)
+	}
+	if rel, err := filepath.Rel(resolvedAppPath, resolvedPath); err != nil || !isSharedLibsDirInWorkspace(resolvedAppPath, rel) {
+		return "", fmt.Errorf("invalid BP_LIBERTY_SHARED_LIBS '%s'; expected a directory inside the workspace", dir)
+	}
+
+	if id := filepath.Base(path); !validLibraryId.MatchString(id) {
+		return "", fmt.Errorf("invalid shared library directory '%s'; its name is used as the library ID, which must start with a letter or underscore and only contain letters, digits, '.', '-' and '_'", dir)
+	}
+	return path, nil
+}
+
+// isSharedLibsDirInWorkspace returns true if dir is a relative path to a directory below the workspace root
+func isSharedLibsDirInWorkspace(appPath string, dir string) bool {
+	if filepath.IsAbs(dir) {
+		return false
+	}
+	rel, err := filepath.Rel(appPath, filepath.Join(appPath, dir))
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// getDefaultFeatures returns the features to enable when the server configuration does not list any. Profiles that
+// package a fixed set of features use their own defaults. Otherwise, the features are inferred from the Jakarta/Java EE
+// version of the application, falling back to the profile defaults if it cannot be determined.
+func (b Build) getDefaultFeatures(profiles server.ProfileCatalog, profileName string, scan util.ApplicationScan) []string {
+	profile, _ := profiles.Get(profileName)
+	if len(profile.Features) > 0 {
+		return profile.DefaultFeatures
+	}
+
+	if features := server.InferDefaultFeatures(scan); len(features) > 0 {
+		b.Logger.Bodyf("Using default features for Jakarta/Java EE %s: %s", scan.PlatformVersion(), strings.Join(features, ", "))
+		return features
+	}
+
+	b.Logger.Debug("Unable to determine the Jakarta/Java EE version of the application -- using profile defaults")
+	return profile.DefaultFeatures
+}
+
+// scanApplication scans the application at the given path. The scan only refines the features chosen by the buildpack,
+// so a failure is logged and an empty scan is returned instead.
+func (b Build) scanApplication(appPath string) *util.ApplicationScan {
+	scan, err := util.ScanApplication(appPath)
+	if err != nil {
+		b.Logger.Info(color.YellowString("Warning: Unable to scan application; continuing without the features it uses\n%s", err))
+		return &util.ApplicationScan{}
+	}
+	return &scan
+}
+
+// selectProfile returns the smallest profile packaged with the buildpack that contains all the required features. The
+// kernel profile is returned if no profile contains them, in which case the features are installed separately.
+func (b Build) selectProfile(
+	profiles server.ProfileCatalog,
+	installType string,
+	version string,
+	features []string,
+	dependencyResolver libpak.DependencyResolver,
+) string {
+	var candidates []server.Profile
+	for _, name := range profiles.Names() {
+		profile, _ := profiles.Get(name)
+		if len(profile.Features) == 0 {
+			continue
+		}
+		if _, err := dependencyResolver.Resolve(fmt.Sprintf("%s-%s", getDistributionType(installType), name), version); err != nil {
+			b.Logger.Debugf("Skipping profile '%s' -- no dependency found\n%s", name, err)
+			continue
+		}
+		candidates = append(candidates, profile)
+	}
+
+	if profile, ok := server.FindSmallestProfile(candidates, features); ok {
+		b.Logger.Bodyf("Selected profile '%s' as it contains all required features: %s", profile, strings.Join(features, ", "))
+		return profile
+	}
+
+	b.Logger.Bodyf("No profile contains all required features; using profile '%s' and installing features: %s", kernelProfile, strings.Join(features, ", "))
+	return kernelProfile
+}
+
+func getDistributionType(installType string) string {
+	if installType == openLibertyInstall {
+		return "open-liberty-runtime"
+	} else if installType == websphereLibertyInstall {
+		return "websphere-liberty-runtime"
+	}
+	return ""
+}
+
 func (b Build) buildDistributionRuntime(
 	profile string,
 	version string,
 	installType string,
-	serverName string,
+	serverNames []string,
+	layersPath string,
 	appPath string,
 	disableFeatureInstall bool,
 	features []string,
 	buildSrc core.BuildSource,
 	sccOptions util.SharedClassCacheOptions,
+	iFixes iFixOptions,
+	jvm util.JVM,
 	dependencyResolver libpak.DependencyResolver,
 	cache libpak.DependencyCache,
-	result *libcnb.BuildResult) error {
+	result *libcnb.BuildResult) (string, error) {
 
-	var distType string
-
-	if installType == openLibertyInstall {
-		distType = "open-liberty-runtime"
-	} else if installType == websphereLibertyInstall {
-		distType = "websphere-liberty-runtime"
-	}
+	distType := getDistributionType(installType)
 
 	dep, err := dependencyResolver.Resolve(fmt.Sprintf("%s-%s", distType, profile), version)
 	if err != nil {
-		return fmt.Errorf("unable to resolve dependency\n%w", err)
+		return "", fmt.Errorf("unable to resolve dependency\n%w", err)
+	}
+
+	if err := server.CheckJavaCompatibility(jvm.Version, dep.Version, nil, b.Logger); err != nil {
+		return "", fmt.Errorf("unable to use the Liberty runtime with the JVM\n%w", err)
 	}
 
 	// Provide the Liberty distribution
-	iFixes, err := server.LoadIFixesList(ifixesRoot)
+	productId := server.OpenLibertyProductId
+	if installType == websphereLibertyInstall {
+		productId = server.WebSphereLibertyProductId
+	}
+	iFixPaths, err := b.loadIFixes(productId, dep.Version, iFixes)
 	if err != nil {
-		return fmt.Errorf("unable to load iFixes\n%w", err)
+		return "", err
 	}
 
-	distro := NewDistribution(dep, cache, installType, serverName, appPath, disableFeatureInstall, features, iFixes, sccOptions, b.Executor)
+	runtime := NewRuntime(dep, cache)
+	runtime.Logger = b.Logger
+
+	distro := NewDistribution(dep, filepath.Join(layersPath, runtime.Name()), installType, serverNames, appPath, disableFeatureInstall, features, iFixPaths, sccOptions, b.Executor)
 	distro.Logger = b.Logger
 
-	result.Layers = append(result.Layers, distro)
-	result.Processes = []libcnb.Process{
-		{
-			Type:      distType,
-			Command:   "server",
-			Arguments: []string{"run", serverName},
-			Default:   true,
-			Direct:    true,
-		},
+	if !disableFeatureInstall {
+		// The cache is contributed first, so that features are installed from it
+		featureCache := NewFeatureCache(dep.Version)
+		featureCache.Logger = b.Logger
+		distro.FeatureCachePath = filepath.Join(layersPath, featureCache.Name())
+		result.Layers = append(result.Layers, featureCache)
+	}
+
+	result.Layers = append(result.Layers, runtime, distro)
+	processes, err := createServerProcesses(libcnb.Process{
+		Type:      distType,
+		Command:   "server",
+		Arguments: []string{"run", serverNames[0]},
+		Default:   true,
+		Direct:    true,
+	}, serverNames)
+	if err != nil {
+		return "", err
 	}
+	result.Processes = processes
 
-	scanPath, err := buildSrc.AppPath()
+	scanPath, err := getScanPath(buildSrc, serverNames)
 	if err != nil {
-		return fmt.Errorf("unable to find scan path\n%s", err)
+		return "", fmt.Errorf("unable to find scan path\n%s", err)
 	}
 
 	if err := b.SBOMScanner.ScanLaunch(scanPath, libcnb.SyftJSON, libcnb.CycloneDXJSON); err != nil {
-		return fmt.Errorf("unable to create Launch SBoM \n%w", err)
+		return "", fmt.Errorf("unable to create Launch SBoM \n%w", err)
 	}
 
-	return nil
+	return distro.Name(), nil
 }
 
-func (b Build) buildStackRuntime(serverName string, result *libcnb.BuildResult) error {
-	process, err := createStackRuntimeProcess(serverName)
+func (b Build) buildBundledRuntime(
+	serverNames []string,
+	buildSrc core.BuildSource,
+	sccOptions util.SharedClassCacheOptions,
+	iFixes iFixOptions,
+	jvm util.JVM,
+	result *libcnb.BuildResult) (string, error) {
+
+	serverBuildSrc, isServer := buildSrc.(core.ServerBuildSource)
+	if !isServer {
+		return "", fmt.Errorf("BP_LIBERTY_INSTALL_TYPE '%s' requires a packaged server", bundledInstall)
+	}
+
+	runtimePath := filepath.Join(serverBuildSrc.InstallRoot, "wlp")
+	if isBundled, err := IsBundledRuntime(runtimePath); err != nil {
+		return "", fmt.Errorf("unable to check for bundled runtime\n%w", err)
+	} else if !isBundled {
+		return "", fmt.Errorf("BP_LIBERTY_INSTALL_TYPE '%s' requires a packaged server with a Liberty runtime in %s",
+			bundledInstall, runtimePath)
+	}
+
+	info, err := server.ReadRuntimeInfo(runtimePath)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("unable to read bundled runtime version\n%w", err)
 	}
-	result.Processes = []libcnb.Process{process}
-	return nil
+	b.Logger.Bodyf("Using bundled %s %s", info.Name, info.Version)
+	if err := server.CheckJavaCompatibility(jvm.Version, info.Version, nil, b.Logger); err != nil {
+		return "", fmt.Errorf("unable to use the bundled runtime with the JVM\n%w", err)
+	}
+
+	iFixPaths, err := b.loadIFixes(info.ProductId, info.Version, iFixes)
+	if err != nil {
+		return "", err
+	}
+
+	runtime := NewBundledRuntime(runtimePath, info, serverNames, iFixPaths, sccOptions, b.Executor, b.Logger)
+	distType := getDistributionType(runtime.Distribution.InstallType)
+
+	result.Layers = append(result.Layers, runtime)
+	processes, err := createServerProcesses(libcnb.Process{
+		Type:      distType,
+		Command:   "server",
+		Arguments: []string{"run", serverNames[0]},
+		Default:   true,
+		Direct:    true,
+	}, serverNames)
+	if err != nil {
+		return "", err
+	}
+	result.Processes = processes
+
+	scanPath, err := getScanPath(buildSrc, serverNames)
+	if err != nil {
+		return "", fmt.Errorf("unable to find scan path\n%w", err)
+	}
+
+	if err := b.SBOMScanner.ScanLaunch(scanPath, libcnb.SyftJSON, libcnb.CycloneDXJSON); err != nil {
+		return "", fmt.Errorf("unable to create Launch SBoM \n%w", err)
+	}
+
+	return runtime.Name(), nil
 }
 
-func createStackRuntimeProcess(serverName string) (libcnb.Process, error) {
-	olExists, err := sherpa.DirExists(openLibertyStackRuntimeRoot)
+// iFixOptions configures where iFixes come from and what happens to iFixes that do not apply to the runtime
+type iFixOptions struct {
+	Policy     string
+	Descriptor *IFixDescriptor
+	Cache      libpak.DependencyCache
+}
+
+// loadIFixes returns the iFixes in the iFixes directory and those listed in `ifixes.toml` that apply to the product and
+// version of the runtime
+func (b Build) loadIFixes(productId string, version string, options iFixOptions) ([]string, error) {
+	iFixes, err := server.LoadIFixesList(ifixesRoot)
 	if err != nil {
-		return libcnb.Process{}, fmt.Errorf("unable to check Open Liberty stack runtime root exists\n%w", err)
+		return nil, fmt.Errorf("unable to load iFixes\n%w", err)
 	}
-	if olExists {
-		return libcnb.Process{
-			Type:      "open-liberty-stack",
-			Command:   "bootstrap.sh",
-			Arguments: []string{"server", "run", serverName},
-			Default:   true,
-			Direct:    true,
-		}, nil
+
+	if options.Descriptor != nil {
+		downloaded, err := options.Descriptor.Download(options.Cache)
+		if err != nil {
+			return nil, fmt.Errorf("unable to download iFixes\n%w", err)
+		}
+		iFixes = append(iFixes, downloaded...)
 	}
 
-	wlpExists, err := sherpa.DirExists(webSphereLibertyRuntimeRoot)
+	iFixes, err = server.SelectIFixes(iFixes, productId, version, options.Policy, b.Logger)
 	if err != nil {
-		return libcnb.Process{}, fmt.Errorf("unable to WebSphere Open Liberty stack runtime root exists\n%w", err)
+		return nil, fmt.Errorf("unable to select iFixes\n%w", err)
 	}
-	if wlpExists {
-		return libcnb.Process{
-			Type:      "websphere-liberty-stack",
-			Command:   "bootstrap.sh",
-			Arguments: []string{"server", "run", serverName},
-			Default:   true,
-			Direct:    true,
-		}, nil
+	return iFixes, nil
+}
+
+func (b Build) buildStackRuntime(serverNames []string, result *libcnb.BuildResult) error {
+	stackRuntime, err := FindStackRuntime()
+	if err != nil {
+		return err
 	}
+	stackRuntime.Logger = b.Logger
 
-	return libcnb.Process{}, fmt.Errorf("unable to find server in the stack image")
+	result.Layers = append(result.Layers, stackRuntime)
+	processes, err := createServerProcesses(libcnb.Process{
+		Type:      stackRuntime.ProcessType,
+		Command:   "bootstrap.sh",
+		Arguments: []string{"server", "run", serverNames[0]},
+		Default:   true,
+		Direct:    true,
+	}, serverNames)
+	if err != nil {
+		return err
+	}
+	result.Processes = processes
+	return nil
+}
+
+// createServerProcesses returns the default process, which runs the first server, a process named after each server
+// when there are several servers, and the debugging and diagnostic processes for the first server. The `server` action
+// and server name are the last two arguments of the default process. An error is returned if the process type of a
+// server is already used by another process.
+func createServerProcesses(defaultProcess libcnb.Process, serverNames []string) ([]libcnb.Process, error) {
+	processes := []libcnb.Process{defaultProcess}
+	reserved := []string{defaultProcess.Type}
+	for _, action := range serverActionProcesses {
+		reserved = append(reserved, action.Type)
+	}
+
+	if len(serverNames) > 1 {
+		for _, serverName := range serverNames {
+			processType := invalidProcessTypeChars.ReplaceAllString(serverName, "-")
+			if slices.Contains(reserved, processType) {
+				return nil, fmt.Errorf("unable to create process type '%s' for server %s as it is already used; rename the server",
+					processType, serverName)
+			}
+			reserved = append(reserved, processType)
+			processes = append(processes, createServerActionProcess(defaultProcess, processType, "run", serverName))
+		}
+	}
+	for _, action := range serverActionProcesses {
+		processes = append(processes, createServerActionProcess(defaultProcess, action.Type, action.Action, serverNames[0], action.Options...))
+	}
+	return processes, nil
+}
+
+func createServerActionProcess(defaultProcess libcnb.Process, processType string, action string, serverName string, options ...string) libcnb.Process {
+	args := slices.Clone(defaultProcess.Arguments[:len(defaultProcess.Arguments)-2])
+	args = append(args, action, serverName)
+	return libcnb.Process{
+		Type:      processType,
+		Command:   defaultProcess.Command,
+		Arguments: append(args, options...),
+		Direct:    defaultProcess.Direct,
+	}
+}
+
+// getScanPath returns the path to scan for the launch SBOM, which includes every server when there are several
+func getScanPath(buildSrc core.BuildSource, serverNames []string) (string, error) {
+	scanPath, err := buildSrc.AppPath()
+	if err != nil || len(serverNames) < 2 {
+		return scanPath, err
+	}
+	return filepath.Dir(scanPath), nil
 }
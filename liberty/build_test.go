@@ -17,6 +17,7 @@
 package liberty_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"io"
 	"os"
@@ -56,16 +57,23 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				{"name": "BP_LIBERTY_VERSION", "default": "21.0.11", "build": true},
 				{"name": "BP_LIBERTY_PROFILE", "default": "", "build": true},
 				{"name": "BP_LIBERTY_INSTALL_TYPE", "default": "ol", "build": true},
+				{"name": "BP_LIBERTY_DEBUG_PORT", "default": "7777", "build": true},
 				{"name": "BP_LIBERTY_SERVER_NAME", "default": "", "build": true},
 				{"name": "BP_LIBERTY_SCC_DISABLED", "default": "false", "build": true},
 				{"name": "BP_LIBERTY_SCC_SIZE_MB", "default": "100", "build": true},
 				{"name": "BP_LIBERTY_SCC_NUM_ITERATIONS", "default": "1", "build": true},
 				{"name": "BP_LIBERTY_SCC_TRIM_SIZE_DISABLED", "default": "false", "build": true},
+				{"name": "BP_LIBERTY_SCC_TRAINING_URLS", "default": "", "build": true},
+				{"name": "BP_LIBERTY_SCC_TRAINING_SCRIPT", "default": "", "build": true},
+				{"name": "BP_LIBERTY_CDS_DISABLED", "default": "false", "build": true},
+				{"name": "BP_LIBERTY_VERIFY", "default": "false", "build": true},
+				{"name": "BP_LIBERTY_VERIFY_URLS", "default": "", "build": true},
 			},
 			"dependencies": []map[string]interface{}{
-				{"id": "open-liberty-runtime-kernel", "version": "21.0.11"},
-				{"id": "websphere-liberty-runtime-kernel", "version": "21.0.11"},
-				{"id": "open-liberty-runtime-jakartaee11", "version": "21.0.11"},
+				{"id": "open-liberty-runtime-kernel", "version": "21.0.11", "default-features": []interface{}{"jsp-2.3"}},
+				{"id": "websphere-liberty-runtime-kernel", "version": "21.0.11", "default-features": []interface{}{"jsp-2.3"}},
+				{"id": "open-liberty-runtime-jakartaee11", "version": "21.0.11", "default-features": []interface{}{"jakartaee-11.0"},
+					"features": []interface{}{"jakartaee-11.0", "webProfile-11.0", "servlet-6.1", "batch-2.1"}},
 			},
 		}
 
@@ -83,16 +91,17 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 		executor.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
 			arg := args.Get(0).(effect.Execution)
-			_, err := arg.Stdout.Write([]byte(`
+			_, err := arg.Stderr.Write([]byte(`
 						java.vendor = IBM Corporation
 						java.vendor.url = https://www.ibm.com/semeru-runtimes
 						java.vendor.url.bug = https://github.com/ibmruntimes/Semeru-Runtimes/issues
-						java.vendor.version = 11.0.16.1
-						java.version = 11.0.16.1
-						java.version.date = 2022-08-12
+						java.vendor.version = 17.0.8
+						java.specification.version = 17
+						java.version = 17.0.8
+						java.version.date = 2023-07-18
 						java.vm.name = Eclipse OpenJ9 VM
 						java.vm.vendor = Eclipse OpenJ9
-						java.vm.version = openj9-0.33.1`),
+						java.vm.version = openj9-0.40.0`),
 			)
 			Expect(err).ToNot(HaveOccurred())
 		}).Return(nil)
@@ -121,10 +130,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(5))
 			Expect(result.Layers[0].Name()).To(Equal("helper"))
 			Expect(result.Layers[1].Name()).To(Equal("base"))
-			Expect(result.Layers[2].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[2].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[3].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-kernel-overlay"))
 
 			sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
 		})
@@ -138,16 +149,517 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(5))
+			Expect(result.Layers[0].Name()).To(Equal("helper"))
+			Expect(result.Layers[1].Name()).To(Equal("base"))
+			Expect(result.Layers[2].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[3].Name()).To(Equal("websphere-liberty-runtime-kernel"))
+			Expect(result.Layers[4].Name()).To(Equal("websphere-liberty-runtime-kernel-overlay"))
+
+			sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
+		})
+
+		it("fails for a profile without a dependency and lists the available profiles", func() {
+			Expect(os.Setenv("BP_LIBERTY_PROFILE", "javaee8")).To(Succeed())
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError("invalid profile 'javaee8' for BP_LIBERTY_INSTALL_TYPE 'ol'; available profiles: kernel, jakartaee11"))
+		})
+
+		it("selects the latest jakartaee11 profile for Open Liberty", func() {
+			Expect(os.Setenv("BP_LIBERTY_PROFILE", "jakartaee11")).To(Succeed())
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(5))
 			Expect(result.Layers[0].Name()).To(Equal("helper"))
 			Expect(result.Layers[1].Name()).To(Equal("base"))
-			Expect(result.Layers[2].Name()).To(Equal("websphere-liberty-runtime-kernel"))
+			Expect(result.Layers[2].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[3].Name()).To(Equal("open-liberty-runtime-jakartaee11"))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-jakartaee11-overlay"))
 
 			sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
 		})
+	})
+
+	context("selecting the Liberty profile automatically", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_PROFILE", "auto")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_PROFILE")).To(Succeed())
+		})
+
+		it("selects the smallest profile containing the required features", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "server.xml"), []byte(`<server>
+  <featureManager>
+    <feature>servlet-6.1</feature>
+    <feature>batch-2.1</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(4))
+			Expect(result.Layers[2].Name()).To(Equal("open-liberty-runtime-jakartaee11"))
+			Expect(result.Layers[3].Name()).To(Equal("open-liberty-runtime-jakartaee11-overlay"))
+			Expect(result.Layers[3].(liberty.Distribution).DisableFeatureInstall).To(BeTrue())
+		})
+
+		it("falls back to the kernel profile when no profile contains the required features", func() {
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(5))
+			Expect(result.Layers[2].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[3].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-kernel-overlay"))
+			Expect(result.Layers[4].(liberty.Distribution).DisableFeatureInstall).To(BeFalse())
+			Expect(result.Layers[4].(liberty.Distribution).FeatureCachePath).To(Equal(filepath.Join(ctx.Layers.Path, "feature-cache")))
+		})
+	})
+
+	context("inferring the default features", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+		})
+
+		it("uses features matching the Jakarta EE version of the application", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "web.xml"),
+				[]byte(`<web-app xmlns="https://jakarta.ee/xml/ns/jakartaee" version="6.0"/>`), 0644)).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).Features).To(Equal([]string{"pages-3.1"}))
+			Expect(result.Layers[4].(liberty.Distribution).Features).To(Equal([]string{"pages-3.1"}))
+		})
+
+		it("uses the profile default features if the version cannot be determined", func() {
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).Features).To(Equal([]string{"jsp-2.3"}))
+		})
+
+		it("does not override the features configured for the server", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "web.xml"),
+				[]byte(`<web-app xmlns="https://jakarta.ee/xml/ns/jakartaee" version="6.0"/>`), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "server.xml"), []byte(`<server>
+  <featureManager>
+    <feature>servlet-6.0</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).Features).To(Equal([]string{"servlet-6.0"}))
+		})
+	})
+
+	context("discovering features", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF", "classes", "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "web.xml"),
+				[]byte(`<web-app xmlns="https://jakarta.ee/xml/ns/jakartaee" version="6.0"/>`), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "beans.xml"), []byte{}, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "classes", "META-INF", "microprofile-config.properties"), []byte{}, 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_FEATURES")).To(Succeed())
+			Expect(os.Unsetenv("BP_LIBERTY_PROFILE")).To(Succeed())
+		})
+
+		it("enables the discovered features when BP_LIBERTY_FEATURES is auto", func() {
+			Expect(os.Setenv("BP_LIBERTY_FEATURES", "auto")).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "server.xml"), []byte(`<server>
+  <featureManager>
+    <feature>mpHealth-4.0</feature>
+  </featureManager>
+</server>`), 0644)).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).Features).To(Equal([]string{"cdi-4.0", "mpConfig-3.1", "mpHealth-4.0", "pages-3.1"}))
+		})
+
+		it("logs the discovered features when the profile is selected automatically", func() {
+			Expect(os.Setenv("BP_LIBERTY_FEATURES", "servlet-6.0")).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_PROFILE", "auto")).To(Succeed())
+			buf := &bytes.Buffer{}
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(buf),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).Features).To(Equal([]string{"servlet-6.0"}))
+			Expect(buf.String()).To(ContainSubstring("Discovered features in the application: pages-3.1, cdi-4.0, mpConfig-3.1"))
+		})
+
+		it("logs the discovered features when the default features are used", func() {
+			Expect(os.Setenv("BP_LIBERTY_PROFILE", "jakartaee11")).To(Succeed())
+			buf := &bytes.Buffer{}
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(buf),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).Features).To(Equal([]string{"jakartaee-11.0"}))
+			Expect(buf.String()).To(ContainSubstring("Discovered features in the application: pages-3.1, cdi-4.0, mpConfig-3.1; set BP_LIBERTY_FEATURES=auto to enable them"))
+		})
+
+		it("does not scan the application when the features are listed", func() {
+			Expect(os.Setenv("BP_LIBERTY_FEATURES", "servlet-6.0")).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "web.xml"), []byte("<web-app"), 0644)).To(Succeed())
+			buf := &bytes.Buffer{}
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(buf),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).Features).To(Equal([]string{"servlet-6.0"}))
+			Expect(buf.String()).NotTo(ContainSubstring("Discovered features"))
+			Expect(buf.String()).NotTo(ContainSubstring("Unable to scan application"))
+		})
+
+		it("warns when the application cannot be scanned for the default features", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "web.xml"), []byte("<web-app"), 0644)).To(Succeed())
+			buf := &bytes.Buffer{}
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(buf),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buf.String()).To(ContainSubstring("Warning: Unable to scan application"))
+			Expect(buf.String()).To(ContainSubstring("unable to parse WEB-INF/web.xml"))
+		})
+
+		it("fails when BP_LIBERTY_FEATURES is auto and the application cannot be scanned", func() {
+			Expect(os.Setenv("BP_LIBERTY_FEATURES", "auto")).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "web.xml"), []byte("<web-app"), 0644)).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to scan application")))
+		})
+	})
+
+	context("building a Spring Boot application", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_JAVA_APP_SERVER", "liberty")).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+				[]byte("Main-Class: org.springframework.boot.loader.JarLauncher\nSpring-Boot-Version: 3.2.1\n"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "BOOT-INF", "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "BOOT-INF", "lib", "spring-core.jar"), []byte("spring-core"), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_JAVA_APP_SERVER")).To(Succeed())
+		})
+
+		it("contributes the library index cache and enables the Spring Boot features", func() {
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(6))
+			Expect(result.Layers[0].Name()).To(Equal("helper"))
+			Expect(result.Layers[1].Name()).To(Equal("base"))
+			Expect(result.Layers[2].Name()).To(Equal("spring-boot-lib-cache"))
+			Expect(result.Layers[3].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[5].Name()).To(Equal("open-liberty-runtime-kernel-overlay"))
+
+			base := result.Layers[1].(liberty.Base)
+			Expect(base.Features).To(Equal([]string{"servlet-6.0", "springBoot-3.0"}))
+			Expect(base.SpringBootLibCache).To(Equal(filepath.Join(ctx.Layers.Path, "spring-boot-lib-cache")))
+			Expect(result.Layers[2].(liberty.SpringBootLibCache).Libs).To(HaveLen(1))
+		})
+	})
+
+	context("overriding web module context roots", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "META-INF", "application.xml"), []byte("<application/>"), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_MODULE_CONTEXT_ROOTS")).To(Succeed())
+		})
+
+		it("passes the module context roots to the base layer", func() {
+			Expect(os.Setenv("BP_LIBERTY_MODULE_CONTEXT_ROOTS", "shop.war=/store admin=/console")).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).ModuleContextRoots).To(Equal(map[string]string{
+				"shop":  "/store",
+				"admin": "/console",
+			}))
+		})
+
+		it("fails for malformed module context roots", func() {
+			Expect(os.Setenv("BP_LIBERTY_MODULE_CONTEXT_ROOTS", "shop")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError("unable to parse BP_LIBERTY_MODULE_CONTEXT_ROOTS\ninvalid module context root 'shop'; expected <module>=<context-root>"))
+		})
+	})
+
+	context("choosing the application deploy mode", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_APP_DEPLOY_MODE")).To(Succeed())
+		})
+
+		it("expands the application by default", func() {
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).AppDeployMode).To(Equal("expanded"))
+		})
+
+		it("passes the deploy mode to the base layer", func() {
+			Expect(os.Setenv("BP_LIBERTY_APP_DEPLOY_MODE", "dropins")).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).AppDeployMode).To(Equal("dropins"))
+		})
+
+		it("fails for unknown deploy modes", func() {
+			Expect(os.Setenv("BP_LIBERTY_APP_DEPLOY_MODE", "loose")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError("invalid BP_LIBERTY_APP_DEPLOY_MODE 'loose'; expected one of: expanded, archive, dropins"))
+		})
+	})
+
+	context("sharing library jars with the application", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_SHARED_LIBS")).To(Succeed())
+		})
+
+		it("uses the lib directory of the workspace", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).SharedLibsPath).To(Equal(filepath.Join(ctx.Application.Path, "lib")))
+		})
+
+		it("uses the directory set by BP_LIBERTY_SHARED_LIBS", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "vendor"), 0755)).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_SHARED_LIBS", "vendor")).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).SharedLibsPath).To(Equal(filepath.Join(ctx.Application.Path, "vendor")))
+		})
+
+		it("does not share the lib directory of an expanded enterprise application", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "META-INF", "application.xml"), []byte(`<application/>`), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "lib"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "lib", "common.jar"), []byte{}, 0644)).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).SharedLibsPath).To(BeEmpty())
+		})
+
+		it("does not share libraries if there is no lib directory", func() {
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[1].(liberty.Base).SharedLibsPath).To(BeEmpty())
+		})
+
+		it("fails if the directory set by BP_LIBERTY_SHARED_LIBS does not exist", func() {
+			Expect(os.Setenv("BP_LIBERTY_SHARED_LIBS", "vendor")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError("unable to find shared library directory 'vendor' set by BP_LIBERTY_SHARED_LIBS"))
+		})
+
+		it("fails if the directory set by BP_LIBERTY_SHARED_LIBS is not inside the workspace", func() {
+			outside := t.TempDir()
+			Expect(os.Symlink(outside, filepath.Join(ctx.Application.Path, "vendor"))).To(Succeed())
+
+			for _, dir := range []string{".", "..", "../lib", outside, "vendor"} {
+				Expect(os.Setenv("BP_LIBERTY_SHARED_LIBS", dir)).To(Succeed())
+
+				_, err := liberty.Build{
+					Logger:      bard.NewLogger(io.Discard),
+					SBOMScanner: &sbomScanner,
+					Executor:    executor,
+				}.Build(ctx)
+				Expect(err).To(MatchError("invalid BP_LIBERTY_SHARED_LIBS '" + dir + "'; expected a directory inside the workspace"))
+			}
+		})
+
+		it("fails if the name of the shared library directory is not a valid library ID", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "3rd party"), 0755)).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_SHARED_LIBS", "3rd party")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("invalid shared library directory '3rd party'")))
+		})
+	})
+
+	context("building a compiled web archive", func() {
+		it.Before(func() {
+			buf := &bytes.Buffer{}
+			writer := zip.NewWriter(buf)
+			for _, name := range []string{"WEB-INF/web.xml", "WEB-INF/lib/commons-lang3.jar"} {
+				_, err := writer.Create(name)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(writer.Close()).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "test.war"), buf.Bytes(), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_APP_DEPLOY_MODE")).To(Succeed())
+		})
+
+		it("contributes the WEB-INF/lib libraries before the base layer", func() {
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(6))
+			Expect(result.Layers[0].Name()).To(Equal("helper"))
+			Expect(result.Layers[1].Name()).To(Equal("web-app-libs"))
+			Expect(result.Layers[2].Name()).To(Equal("base"))
+			Expect(result.Layers[3].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[5].Name()).To(Equal("open-liberty-runtime-kernel-overlay"))
+
+			webAppLibs := result.Layers[1].(liberty.WebAppLibs)
+			Expect(webAppLibs.Libs).To(HaveLen(1))
+			Expect(webAppLibs.Libs[0].Entry).To(Equal("WEB-INF/lib/commons-lang3.jar"))
+			Expect(result.Layers[2].(liberty.Base).WebAppLibsPath).To(Equal(filepath.Join(ctx.Layers.Path, "web-app-libs")))
+		})
+
+		it("keeps the libraries in the archive if it is not expanded", func() {
+			Expect(os.Setenv("BP_LIBERTY_APP_DEPLOY_MODE", "archive")).To(Succeed())
 
-		it("selects the latest jakartaee11 profile for Open Liberty", func() {
-			Expect(os.Setenv("BP_LIBERTY_PROFILE", "jakartaee11")).To(Succeed())
 			result, err := liberty.Build{
 				Logger:      bard.NewLogger(io.Discard),
 				SBOMScanner: &sbomScanner,
@@ -155,12 +667,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
-			Expect(result.Layers[0].Name()).To(Equal("helper"))
+			Expect(result.Layers).To(HaveLen(5))
 			Expect(result.Layers[1].Name()).To(Equal("base"))
-			Expect(result.Layers[2].Name()).To(Equal("open-liberty-runtime-jakartaee11"))
-
-			sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
 		})
 	})
 
@@ -281,10 +789,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(5))
 			Expect(result.Layers[0].Name()).To(Equal("helper"))
 			Expect(result.Layers[1].Name()).To(Equal("base"))
-			Expect(result.Layers[2].Name()).To(Equal("open-liberty-runtime-jakartaee11"))
+			Expect(result.Layers[2].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[3].Name()).To(Equal("open-liberty-runtime-jakartaee11"))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-jakartaee11-overlay"))
 
 			sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
 		})
@@ -319,10 +829,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			}.Build(ctx)
 
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(5))
 			Expect(result.Layers[0].Name()).To(Equal("helper"))
 			Expect(result.Layers[1].Name()).To(Equal("base"))
-			Expect(result.Layers[2].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[2].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[3].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-kernel-overlay"))
 			Expect(result.Unmet).To(HaveLen(0))
 
 			sbomScanner.AssertCalled(t, "ScanLaunch", filepath.Join(ctx.Application.Path, "usr", "servers", "defaultServer"), libcnb.SyftJSON, libcnb.CycloneDXJSON)
@@ -345,6 +857,431 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("when building a server package archive", func() {
+		it.Before(func() {
+			buf := &bytes.Buffer{}
+			writer := zip.NewWriter(buf)
+			w, err := writer.Create("usr/servers/defaultServer/server.xml")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte("<server/>"))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = writer.Create("usr/servers/defaultServer/apps/test.war")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writer.Close()).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "defaultServer.zip"), buf.Bytes(), 0644)).To(Succeed())
+		})
+
+		it("extracts the package and builds the server", func() {
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Layers).To(HaveLen(5))
+			Expect(result.Layers[1].Name()).To(Equal("base"))
+			Expect(result.Unmet).To(HaveLen(0))
+
+			Expect(filepath.Join(ctx.Application.Path, "usr", "servers", "defaultServer", "server.xml")).To(BeARegularFile())
+			Expect(filepath.Join(ctx.Application.Path, "defaultServer.zip")).ToNot(BeAnExistingFile())
+			sbomScanner.AssertCalled(t, "ScanLaunch", filepath.Join(ctx.Application.Path, "usr", "servers", "defaultServer"), libcnb.SyftJSON, libcnb.CycloneDXJSON)
+		})
+	})
+
+	context("choosing the iFix policy", func() {
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_IFIX_POLICY")).To(Succeed())
+		})
+
+		it("fails for an invalid policy", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_IFIX_POLICY", "ignore")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError("invalid BP_LIBERTY_IFIX_POLICY 'ignore'; expected one of: skip, fail"))
+		})
+	})
+
+	context("checking the Java version", func() {
+		var java11 *effectMocks.Executor
+
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+			java11 = &effectMocks.Executor{}
+			java11.On("Execute", mock.Anything).Run(func(args mock.Arguments) {
+				_, err := args.Get(0).(effect.Execution).Stderr.Write([]byte(`
+						java.specification.version = 11
+						java.vendor = BellSoft
+						java.version = 11.0.16.1
+						java.vm.name = OpenJDK 64-Bit Server VM`))
+				Expect(err).ToNot(HaveOccurred())
+			}).Return(nil)
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_FEATURES")).To(Succeed())
+			Expect(os.Unsetenv("BP_LIBERTY_VERSION")).To(Succeed())
+		})
+
+		it("fails if a feature requires a later Java version", func() {
+			Expect(os.Setenv("BP_LIBERTY_FEATURES", "servlet-6.1")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    java11,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("feature servlet-6.1 requires Java 17 or later, but the JVM is Java 11")))
+		})
+
+		it("fails if the Liberty version does not support the Java version", func() {
+			ctx.Buildpack.Metadata["dependencies"] = []map[string]interface{}{
+				{"id": "open-liberty-runtime-kernel", "version": "21.0.9", "default-features": []interface{}{"jsp-2.3"}},
+			}
+			Expect(os.Setenv("BP_LIBERTY_VERSION", "21.0.9")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("Liberty 21.0.0.9 does not support Java 17; Java 17 requires Liberty 21.0.0.10 or later")))
+		})
+
+		it("accepts features supported by the Java version", func() {
+			Expect(os.Setenv("BP_LIBERTY_FEATURES", "servlet-6.0")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    java11,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("skips the CDS archive before Java 13", func() {
+			buf := &bytes.Buffer{}
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(buf),
+				SBOMScanner: &sbomScanner,
+				Executor:    java11,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(5))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-kernel-overlay"))
+			Expect(buf.String()).To(ContainSubstring("Skipping the CDS archive as it requires Java 13 or later, but the JVM is Java 11"))
+		})
+	})
+
+	context("training the shared class cache", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_SCC_TRAINING_URLS")).To(Succeed())
+			Expect(os.Unsetenv("BP_LIBERTY_SCC_NUM_ITERATIONS")).To(Succeed())
+		})
+
+		it("accepts paths, URLs and automatic iterations", func() {
+			Expect(os.Setenv("BP_LIBERTY_SCC_TRAINING_URLS", "/ http://localhost:9080/api/health")).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_SCC_NUM_ITERATIONS", "auto")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("fails for an invalid training URL", func() {
+			Expect(os.Setenv("BP_LIBERTY_SCC_TRAINING_URLS", "ftp://localhost/file")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("invalid BP_LIBERTY_SCC_TRAINING_URLS entry 'ftp://localhost/file'")))
+		})
+	})
+
+	context("verifying the server", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_VERIFY", "true")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_VERIFY")).To(Succeed())
+			Expect(os.Unsetenv("BP_LIBERTY_VERIFY_URLS")).To(Succeed())
+		})
+
+		it("verifies the server once the runtime is contributed", func() {
+			Expect(os.Setenv("BP_LIBERTY_VERIFY_URLS", "/health http://localhost:9443/api")).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(6))
+			Expect(result.Layers[5].Name()).To(Equal("verify"))
+			verifier := result.Layers[5].(liberty.Verifier)
+			Expect(verifier.RuntimePath).To(Equal(filepath.Join(ctx.Layers.Path, "open-liberty-runtime-kernel-overlay")))
+			Expect(verifier.ServerNames).To(Equal([]string{"defaultServer"}))
+			Expect(verifier.EndpointURLs).To(Equal([]string{"http://localhost:9080/health", "http://localhost:9443/api"}))
+		})
+
+		it("fails for an invalid endpoint URL", func() {
+			Expect(os.Setenv("BP_LIBERTY_VERIFY_URLS", "localhost:9080")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("invalid BP_LIBERTY_VERIFY_URLS entry 'localhost:9080'")))
+		})
+	})
+
+	context("contributing debugging and diagnostic processes", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_DEBUG_PORT")).To(Succeed())
+		})
+
+		it("contributes processes for the server actions", func() {
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Processes).To(Equal([]libcnb.Process{
+				{Type: "open-liberty-runtime", Command: "server", Arguments: []string{"run", "defaultServer"}, Default: true, Direct: true},
+				{Type: "debug", Command: "server", Arguments: []string{"debug", "defaultServer"}, Direct: true},
+				{Type: "clean", Command: "server", Arguments: []string{"run", "defaultServer", "--clean"}, Direct: true},
+				{Type: "dump", Command: "server", Arguments: []string{"dump", "defaultServer"}, Direct: true},
+				{Type: "javadump", Command: "server", Arguments: []string{"javadump", "defaultServer"}, Direct: true},
+			}))
+			Expect(result.Layers[1].(liberty.Base).DebugPort).To(Equal("7777"))
+		})
+
+		it("configures the debug port", func() {
+			Expect(os.Setenv("BP_LIBERTY_DEBUG_PORT", "8787")).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Layers[1].(liberty.Base).DebugPort).To(Equal("8787"))
+		})
+
+		it("fails for an invalid debug port", func() {
+			Expect(os.Setenv("BP_LIBERTY_DEBUG_PORT", "debug")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError("invalid BP_LIBERTY_DEBUG_PORT 'debug'; expected a port number"))
+		})
+	})
+
+	context("when building several servers", func() {
+		it.Before(func() {
+			for _, name := range []string{"defaultServer", "stub.server"} {
+				serverPath := filepath.Join(ctx.Application.Path, "wlp", "usr", "servers", name)
+				Expect(os.MkdirAll(filepath.Join(serverPath, "apps"), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte("<server/>"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(serverPath, "apps", "test.war"), []byte{}, 0644)).To(Succeed())
+			}
+			sbomScanner.On("ScanLaunch", filepath.Join(ctx.Application.Path, "wlp", "usr", "servers"), libcnb.SyftJSON, libcnb.CycloneDXJSON).Return(nil)
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_SERVER_NAME")).To(Succeed())
+		})
+
+		it("contributes a process for each server", func() {
+			Expect(os.Setenv("BP_LIBERTY_SERVER_NAME", "*")).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(5))
+			Expect(result.Layers[4].(liberty.Distribution).ServerNames).To(Equal([]string{"defaultServer", "stub.server"}))
+			Expect(result.Processes).To(Equal([]libcnb.Process{
+				{Type: "open-liberty-runtime", Command: "server", Arguments: []string{"run", "defaultServer"}, Default: true, Direct: true},
+				{Type: "defaultServer", Command: "server", Arguments: []string{"run", "defaultServer"}, Direct: true},
+				{Type: "stub-server", Command: "server", Arguments: []string{"run", "stub.server"}, Direct: true},
+				{Type: "debug", Command: "server", Arguments: []string{"debug", "defaultServer"}, Direct: true},
+				{Type: "clean", Command: "server", Arguments: []string{"run", "defaultServer", "--clean"}, Direct: true},
+				{Type: "dump", Command: "server", Arguments: []string{"dump", "defaultServer"}, Direct: true},
+				{Type: "javadump", Command: "server", Arguments: []string{"javadump", "defaultServer"}, Direct: true},
+			}))
+			sbomScanner.AssertCalled(t, "ScanLaunch", filepath.Join(ctx.Application.Path, "wlp", "usr", "servers"), libcnb.SyftJSON, libcnb.CycloneDXJSON)
+		})
+
+		it("uses the first listed server as the default", func() {
+			Expect(os.Setenv("BP_LIBERTY_SERVER_NAME", "stub.server,defaultServer")).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Processes[0]).To(Equal(libcnb.Process{
+				Type: "open-liberty-runtime", Command: "server", Arguments: []string{"run", "stub.server"}, Default: true, Direct: true,
+			}))
+			Expect(result.Processes).To(ContainElement(libcnb.Process{
+				Type: "debug", Command: "server", Arguments: []string{"debug", "stub.server"}, Direct: true,
+			}))
+			Expect(result.Processes).To(HaveLen(7))
+		})
+
+		it("fails if a server name clashes with another process type", func() {
+			serverPath := filepath.Join(ctx.Application.Path, "wlp", "usr", "servers", "debug")
+			Expect(os.MkdirAll(serverPath, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte("<server/>"), 0644)).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_SERVER_NAME", "defaultServer,debug")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to create process type 'debug' for server debug as it is already used")))
+		})
+
+		it("fails if server names map to the same process type", func() {
+			serverPath := filepath.Join(ctx.Application.Path, "wlp", "usr", "servers", "stub-server")
+			Expect(os.MkdirAll(serverPath, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte("<server/>"), 0644)).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_SERVER_NAME", "*")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to create process type 'stub-server' for server")))
+		})
+	})
+
+	context("when several servers are requested for an application", func() {
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_SERVER_NAME")).To(Succeed())
+		})
+
+		it("fails", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+			Expect(os.Setenv("BP_LIBERTY_SERVER_NAME", "defaultServer,stubServer")).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("BP_LIBERTY_SERVER_NAME may only select several servers when building a packaged server")))
+		})
+	})
+
+	context("when using the runtime bundled in a packaged server", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_LIBERTY_INSTALL_TYPE", "bundled")).To(Succeed())
+			sbomScanner.On("ScanLaunch", filepath.Join(ctx.Application.Path, "wlp", "usr", "servers", "defaultServer"), libcnb.SyftJSON, libcnb.CycloneDXJSON).Return(nil)
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_LIBERTY_INSTALL_TYPE")).To(Succeed())
+		})
+
+		it("contributes the bundled runtime", func() {
+			runtimePath := filepath.Join(ctx.Application.Path, "wlp")
+			Expect(os.MkdirAll(filepath.Join(runtimePath, "bin"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(runtimePath, "lib", "versions"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(runtimePath, "lib", "versions", "openliberty.properties"), []byte(`com.ibm.websphere.productId=io.openliberty
+com.ibm.websphere.productName=Open Liberty
+com.ibm.websphere.productVersion=24.0.0.6
+`), 0644)).To(Succeed())
+			serverPath := filepath.Join(runtimePath, "usr", "servers", "defaultServer")
+			Expect(os.MkdirAll(filepath.Join(serverPath, "apps"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte("<server/>"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(serverPath, "apps", "test.war"), []byte{}, 0644)).To(Succeed())
+
+			result, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers[1].Name()).To(Equal("base"))
+			Expect(result.Layers[2].Name()).To(Equal("bundled-runtime"))
+			Expect(result.Processes).To(ContainElement(libcnb.Process{
+				Type:      "open-liberty-runtime",
+				Command:   "server",
+				Arguments: []string{"run", "defaultServer"},
+				Default:   true,
+				Direct:    true,
+			}))
+		})
+
+		it("fails when the packaged server has no runtime", func() {
+			serverPath := filepath.Join(ctx.Application.Path, "wlp", "usr", "servers", "defaultServer")
+			Expect(os.MkdirAll(filepath.Join(serverPath, "apps"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte("<server/>"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(serverPath, "apps", "test.war"), []byte{}, 0644)).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+
+			Expect(err).To(MatchError(ContainSubstring("BP_LIBERTY_INSTALL_TYPE 'bundled' requires a packaged server with a Liberty runtime")))
+		})
+
+		it("fails when not building a packaged server", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+			_, err := liberty.Build{
+				Logger:      bard.NewLogger(io.Discard),
+				SBOMScanner: &sbomScanner,
+				Executor:    executor,
+			}.Build(ctx)
+
+			Expect(err).To(MatchError("BP_LIBERTY_INSTALL_TYPE 'bundled' requires a packaged server"))
+		})
+	})
+
 	context("when building a compiled artifact and server config", func() {
 		it("should discover the app", func() {
 			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "test.war"), []byte{}, 0644)).To(Succeed())
@@ -355,10 +1292,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			}.Build(ctx)
 
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(5))
 			Expect(result.Layers[0].Name()).To(Equal("helper"))
 			Expect(result.Layers[1].Name()).To(Equal("base"))
-			Expect(result.Layers[2].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[2].Name()).To(Equal("feature-cache"))
+			Expect(result.Layers[3].Name()).To(Equal("open-liberty-runtime-kernel"))
+			Expect(result.Layers[4].Name()).To(Equal("open-liberty-runtime-kernel-overlay"))
 			Expect(result.Unmet).To(HaveLen(0))
 
 			sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// bundledRuntimeExcludes are the entries of a bundled `wlp` directory that are not part of the runtime
+var bundledRuntimeExcludes = []string{"usr", "output"}
+
+// BundledRuntime contributes the Liberty runtime bundled in a packaged server as the runtime layer, instead of
+// downloading a distribution. It is configured the same way as a Distribution once copied, but features are not
+// installed as the bundled runtime is used exactly as it was packaged.
+type BundledRuntime struct {
+	RuntimePath      string
+	Distribution     Distribution
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+}
+
+func NewBundledRuntime(
+	runtimePath string,
+	info server.RuntimeInfo,
+	serverNames []string,
+	ifixes []string,
+	sccOptions util.SharedClassCacheOptions,
+	executor effect.Executor,
+	logger bard.Logger,
+) BundledRuntime {
+	runtimeSum, err := sherpa.NewFileListingHash(bundledRuntimeEntries(runtimePath)...)
+	if err != nil {
+		logger.Info(color.RedString("unable to calculate checksum of bundled runtime\n%w", err))
+	}
+
+	installType := websphereLibertyInstall
+	if info.IsOpenLiberty() {
+		installType = openLibertyInstall
+	}
+
+	contributor := libpak.NewLayerContributor(
+		fmt.Sprintf("Bundled %s %s", info.Name, info.Version),
+		map[string]interface{}{
+			"runtime":      info,
+			"runtimeSum":   runtimeSum,
+			"server-names": serverNames,
+			"ifixes":       iFixDigests(ifixes),
+		},
+		libcnb.LayerTypes{
+			Cache:  true,
+			Launch: true,
+		})
+
+	return BundledRuntime{
+		RuntimePath: runtimePath,
+		Distribution: Distribution{
+			Dependency:            bundledRuntimeDependency(info),
+			InstallType:           installType,
+			ServerNames:           serverNames,
+			Executor:              executor,
+			DisableFeatureInstall: true,
+			IFixes:                ifixes,
+			Logger:                logger,
+			sccOptions:            sccOptions,
+		},
+		LayerContributor: contributor,
+		Logger:           logger,
+	}
+}
+
+func (b BundledRuntime) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	b.LayerContributor.Logger = b.Logger
+
+	layer, err := b.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+		b.Logger.Bodyf("Copying bundled runtime to %s", layer.Path)
+		for _, entry := range bundledRuntimeEntries(b.RuntimePath) {
+			if err := copyPath(entry, filepath.Join(layer.Path, filepath.Base(entry))); err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to copy bundled runtime\n%w", err)
+			}
+		}
+		return b.Distribution.configureRuntime(layer)
+	})
+	if err != nil {
+		return libcnb.Layer{}, err
+	}
+
+	// The runtime now lives in the layer, so only the user directory is kept in the workspace
+	for _, entry := range bundledRuntimeEntries(b.RuntimePath) {
+		if err := os.RemoveAll(entry); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to remove bundled runtime from workspace\n%w", err)
+		}
+	}
+	return layer, nil
+}
+
+func (BundledRuntime) Name() string {
+	return "bundled-runtime"
+}
+
+// IsBundledRuntime returns true if the `wlp` directory contains a Liberty runtime rather than only a user directory.
+func IsBundledRuntime(runtimePath string) (bool, error) {
+	for _, dir := range []string{"bin", "lib"} {
+		if exists, err := sherpa.DirExists(filepath.Join(runtimePath, dir)); err != nil || !exists {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// bundledRuntimeDependency describes the bundled runtime for the SBOM in the same way as the distributions in
+// buildpack.toml
+func bundledRuntimeDependency(info server.RuntimeInfo) libpak.BuildpackDependency {
+	if info.IsOpenLiberty() {
+		return libpak.BuildpackDependency{
+			ID:      "open-liberty-runtime-bundled",
+			Name:    info.Name,
+			Version: info.Version,
+			PURL:    fmt.Sprintf("pkg:maven/io.openliberty/openliberty-runtime@%s", info.Version),
+			CPEs:    []string{fmt.Sprintf("cpe:2.3:a:ibm:open_liberty:%s:*:*:*:*:*:*:*", info.Version)},
+		}
+	}
+	return libpak.BuildpackDependency{
+		ID:      "websphere-liberty-runtime-bundled",
+		Name:    info.Name,
+		Version: info.Version,
+		PURL:    fmt.Sprintf("pkg:maven/com.ibm.websphere.appserver.runtime/wlp-kernel@%s", info.Version),
+		CPEs:    []string{fmt.Sprintf("cpe:2.3:a:ibm:websphere_application_server:%s:*:*:*:liberty:*:*:*", info.Version)},
+	}
+}
+
+func bundledRuntimeEntries(runtimePath string) []string {
+	entries, err := os.ReadDir(runtimePath)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !slices.Contains(bundledRuntimeExcludes, entry.Name()) {
+			paths = append(paths, filepath.Join(runtimePath, entry.Name()))
+		}
+	}
+	return paths
+}
+
+func copyPath(source string, destination string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return sherpa.CopyDir(source, destination)
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return sherpa.CopyFile(in, destination)
+}
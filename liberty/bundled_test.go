@@ -0,0 +1,133 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/paketo-buildpacks/liberty/liberty"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/paketo-buildpacks/libpak/effect/mocks"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+func testBundledRuntime(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect      = NewWithT(t).Expect
+		executor    *mocks.Executor
+		ctx         libcnb.BuildContext
+		runtimePath string
+		info        = server.RuntimeInfo{ProductId: server.OpenLibertyProductId, Name: "Open Liberty", Version: "24.0.0.6"}
+	)
+
+	it.Before(func() {
+		var err error
+
+		ctx.Layers.Path, err = os.MkdirTemp("", "bundled-layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx.Application.Path, err = os.MkdirTemp("", "bundled-app")
+		Expect(err).NotTo(HaveOccurred())
+
+		runtimePath = filepath.Join(ctx.Application.Path, "wlp")
+		Expect(os.MkdirAll(filepath.Join(runtimePath, "bin"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "bin", "server"), []byte{}, 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(runtimePath, "lib", "versions"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "lib", "versions", "openliberty.properties"), []byte{}, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "README.TXT"), []byte{}, 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(runtimePath, "usr", "servers", "defaultServer"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "usr", "servers", "defaultServer", "server.xml"), []byte("<server/>"), 0644)).To(Succeed())
+
+		executor = &mocks.Executor{}
+		executor.On("Execute", mock.Anything).Return(nil)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+		Expect(os.RemoveAll(ctx.Application.Path)).To(Succeed())
+	})
+
+	it("detects a bundled runtime", func() {
+		Expect(liberty.IsBundledRuntime(runtimePath)).To(BeTrue())
+
+		Expect(os.RemoveAll(filepath.Join(runtimePath, "lib"))).To(Succeed())
+		Expect(liberty.IsBundledRuntime(runtimePath)).To(BeFalse())
+	})
+
+	it("moves the bundled runtime to the layer", func() {
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		runtime := liberty.NewBundledRuntime(runtimePath, info, []string{"defaultServer"}, []string{}, util.SharedClassCacheOptions{}, executor, bard.NewLogger(io.Discard))
+		Expect(runtime.Distribution.InstallType).To(Equal("ol"))
+		Expect(runtime.Distribution.Dependency.PURL).To(Equal("pkg:maven/io.openliberty/openliberty-runtime@24.0.0.6"))
+		Expect(runtime.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("runtime", info))
+		Expect(runtime.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("server-names", []string{"defaultServer"}))
+
+		layer, err = runtime.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Launch).To(BeTrue())
+		Expect(filepath.Join(layer.Path, "bin", "server")).To(BeARegularFile())
+		Expect(filepath.Join(layer.Path, "lib", "versions", "openliberty.properties")).To(BeARegularFile())
+		Expect(filepath.Join(layer.Path, "README.TXT")).To(BeARegularFile())
+		Expect(filepath.Join(layer.Path, "usr")).NotTo(BeADirectory())
+		Expect(layer.LaunchEnvironment["BPI_LIBERTY_RUNTIME_ROOT.default"]).To(Equal(layer.Path))
+
+		Expect(filepath.Join(runtimePath, "bin")).NotTo(BeADirectory())
+		Expect(filepath.Join(runtimePath, "lib")).NotTo(BeADirectory())
+		Expect(filepath.Join(runtimePath, "usr", "servers", "defaultServer", "server.xml")).To(BeARegularFile())
+
+		for _, call := range executor.Calls {
+			Expect(call.Arguments[0].(effect.Execution).Command).NotTo(HaveSuffix("featureUtility"))
+		}
+	})
+
+	it("installs iFixes to the bundled runtime", func() {
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		iFixPath := filepath.Join(ctx.Application.Path, "210012-wlp-archive-ifph12345.jar")
+		Expect(os.WriteFile(iFixPath, []byte{}, 0644)).To(Succeed())
+
+		runtime := liberty.NewBundledRuntime(runtimePath, info, []string{"defaultServer"}, []string{iFixPath}, util.SharedClassCacheOptions{}, executor, bard.NewLogger(io.Discard))
+		layer, err = runtime.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		installIFixExecution := executor.Calls[0].Arguments[0].(effect.Execution)
+		Expect(installIFixExecution.Command).To(Equal("java"))
+		Expect(installIFixExecution.Args).To(Equal([]string{"-jar", iFixPath, "--installLocation", layer.Path}))
+	})
+
+	it("describes a bundled WebSphere Liberty runtime", func() {
+		wlpInfo := server.RuntimeInfo{ProductId: server.WebSphereLibertyProductId, Name: "WebSphere Application Server", Version: "24.0.0.6"}
+		runtime := liberty.NewBundledRuntime(runtimePath, wlpInfo, []string{"defaultServer"}, []string{}, util.SharedClassCacheOptions{}, executor, bard.NewLogger(io.Discard))
+
+		Expect(runtime.Distribution.InstallType).To(Equal("wlp"))
+		Expect(runtime.Distribution.Dependency.PURL).To(Equal("pkg:maven/com.ibm.websphere.appserver.runtime/wlp-kernel@24.0.0.6"))
+	})
+}
@@ -47,10 +47,12 @@ func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error
 	serverName, _ := cr.Resolve("BP_LIBERTY_SERVER_NAME")
 	requestedAppServer, _ := cr.Resolve("BP_JAVA_APP_SERVER")
 	serverBuildSrc := core.NewServerBuildSource(context.Application.Path, serverName, d.Logger)
+	springBootBuildSrc := core.NewSpringBootBuildSource(context.Application.Path, requestedAppServer, d.Logger)
 	appBuildSrc := core.NewAppBuildSource(context.Application.Path, requestedAppServer, d.Logger)
 
 	buildSources := []core.BuildSource{
 		serverBuildSrc,
+		springBootBuildSrc,
 		appBuildSrc,
 	}
 
@@ -17,6 +17,7 @@
 package liberty_test
 
 import (
+	"archive/zip"
 	"os"
 	"path/filepath"
 	"testing"
@@ -205,6 +206,32 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		Expect(result).To(Equal(libcnb.DetectResult{Pass: false}))
 	})
 
+	context("when building a Spring Boot application", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+				[]byte("Main-Class: org.springframework.boot.loader.JarLauncher\nSpring-Boot-Version: 3.2.1\n"), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_JAVA_APP_SERVER")).To(Succeed())
+		})
+
+		it("fails if Liberty is not requested", func() {
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(libcnb.DetectResult{Pass: false}))
+		})
+
+		it("passes and provides the application package if Liberty is requested", func() {
+			Expect(os.Setenv("BP_JAVA_APP_SERVER", "liberty")).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+			Expect(result.Plans[0].Provides).To(ContainElement(libcnb.BuildPlanProvide{Name: liberty.PlanEntryJVMApplicationPackage}))
+		})
+	})
+
 	context("an unrecognized app server is set", func() {
 		it.Before(func() {
 			Expect(os.Setenv("BP_JAVA_APP_SERVER", "foo")).To(Succeed())
@@ -372,6 +399,28 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("when building a server package archive", func() {
+		it.Before(func() {
+			out, err := os.Create(filepath.Join(ctx.Application.Path, "defaultServer.jar"))
+			Expect(err).NotTo(HaveOccurred())
+			writer := zip.NewWriter(out)
+			for _, name := range []string{"wlp/usr/servers/defaultServer/server.xml", "wlp/usr/servers/defaultServer/dropins/test.war"} {
+				_, err := writer.Create(name)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(writer.Close()).To(Succeed())
+			Expect(out.Close()).To(Succeed())
+		})
+
+		it("passes and provides the application package without extracting it", func() {
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+			Expect(result.Plans[0].Provides).To(ContainElement(libcnb.BuildPlanProvide{Name: liberty.PlanEntryJVMApplicationPackage}))
+			Expect(filepath.Join(ctx.Application.Path, "wlp")).ToNot(BeADirectory())
+		})
+	})
+
 	context("when building a server directory", func() {
 		it.Before(func() {
 			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "usr", "servers", "defaultServer", "apps", "test.war"), 0755)).To(Succeed())
@@ -24,31 +24,50 @@ import (
 	"github.com/paketo-buildpacks/libjvm/count"
 	"github.com/paketo-buildpacks/libpak"
 	"github.com/paketo-buildpacks/libpak/bard"
-	"github.com/paketo-buildpacks/libpak/crush"
 	"github.com/paketo-buildpacks/libpak/effect"
 	"github.com/paketo-buildpacks/libpak/sbom"
+	"github.com/paketo-buildpacks/libpak/sherpa"
 	"golang.org/x/sys/unix"
 	"io"
+	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	cacheName string = "liberty"
+
+	cdsArchiveName = "liberty.jsa"
+
+	// maxAutoIterations limits how often the server is cycled when the SCC iterations are `auto`
+	maxAutoIterations = 10
+	// stableFillRatioDelta is the growth of the SCC fill ratio below which another iteration is not worth it
+	stableFillRatioDelta = 0.01
+	// trainingTimeout is how long to wait for the application to respond to a training URL
+	trainingTimeout = 2 * time.Minute
+	// serverReadyTimeout is how long to wait for the server to start its applications
+	serverReadyTimeout = 5 * time.Minute
 )
 
+// Distribution contributes the Liberty runtime with the features and iFixes of the application installed. It starts
+// from a copy of the Runtime layer and links the files left unchanged back to it, so that only the installed features
+// and iFixes are added to the image.
 type Distribution struct {
 	Dependency            libpak.BuildpackDependency
+	RuntimePath           string
 	ApplicationPath       string
 	InstallType           string
-	ServerName            string
+	ServerNames           []string
 	Executor              effect.Executor
 	DisableFeatureInstall bool
+	FeatureCachePath      string
 	Features              []string
 	IFixes                []string
-	LayerContributor      libpak.DependencyLayerContributor
+	LayerContributor      libpak.LayerContributor
 	Logger                bard.Logger
 
 	sccOptions util.SharedClassCacheOptions
@@ -56,9 +75,9 @@ type Distribution struct {
 
 func NewDistribution(
 	dependency libpak.BuildpackDependency,
-	cache libpak.DependencyCache,
+	runtimePath string,
 	installType string,
-	serverName string,
+	serverNames []string,
 	applicationPath string,
 	disableFeatureInstall bool,
 	features []string,
@@ -66,23 +85,25 @@ func NewDistribution(
 	sccOptions util.SharedClassCacheOptions,
 	executor effect.Executor,
 ) Distribution {
-	contributor, _ := libpak.NewDependencyLayer(dependency, cache, libcnb.LayerTypes{
-		Cache:  true,
-		Launch: true,
-	})
-
-	contributor.ExpectedMetadata = map[string]interface{}{
-		"dependency":  dependency,
-		"server-name": serverName,
-		"features":    features,
-		"ifixes":      ifixes,
-	}
+	contributor := libpak.NewLayerContributor(
+		fmt.Sprintf("%s features and iFixes", dependency.Name),
+		map[string]interface{}{
+			"dependency":   dependency,
+			"server-names": serverNames,
+			"features":     features,
+			"ifixes":       iFixDigests(ifixes),
+		},
+		libcnb.LayerTypes{
+			Cache:  true,
+			Launch: true,
+		})
 
 	return Distribution{
 		Dependency:            dependency,
+		RuntimePath:           runtimePath,
 		InstallType:           installType,
 		ApplicationPath:       applicationPath,
-		ServerName:            serverName,
+		ServerNames:           serverNames,
 		Executor:              executor,
 		DisableFeatureInstall: disableFeatureInstall,
 		Features:              features,
@@ -95,65 +116,159 @@ func NewDistribution(
 
 func (d Distribution) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 	d.LayerContributor.Logger = d.Logger
-	return d.LayerContributor.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
-		d.Logger.Bodyf("Expanding to %s", layer.Path)
-		if err := crush.ExtractZip(artifact, layer.Path, 1); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to expand Liberty Runtime\n%w", err)
+	return d.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+		d.Logger.Bodyf("Copying runtime to %s", layer.Path)
+		if err := sherpa.CopyDir(d.RuntimePath, layer.Path); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to copy Liberty Runtime\n%w", err)
+		}
+		if err := copyModTimes(d.RuntimePath, layer.Path); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to copy modification times of Liberty Runtime\n%w", err)
 		}
 
-		if !d.DisableFeatureInstall {
-			if err := server.InstallFeatures(layer.Path, d.ServerName, d.Executor, d.Logger); err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to install features to distribution\n%w", err)
-			}
-		} else {
-			d.Logger.Debug("Skipping feature installation")
+		layer, err := d.configureRuntime(layer)
+		if err != nil {
+			return libcnb.Layer{}, err
 		}
 
-		if err := server.InstallIFixes(layer.Path, d.IFixes, d.Executor, d.Logger); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to install iFixes to distribution\n%w", err)
+		if err := linkUnchangedFiles(d.RuntimePath, layer.Path); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to link runtime files\n%w", err)
 		}
+		return layer, nil
+	})
+}
 
-		// Create the output directory for Liberty
-		outputDir := filepath.Join(layer.Path, "output")
-		if err := createOutputDirectory(outputDir); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to create output directory\n%w", err)
+// copyModTimes sets the modification time of the files of the runtime copy to that of the files in the runtime, which
+// CopyDir does not keep, so that linkUnchangedFiles can tell the files changed while configuring the runtime.
+func copyModTimes(runtimePath string, copyPath string) error {
+	return filepath.WalkDir(runtimePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
 		}
-		layer.LaunchEnvironment.Override("WLP_OUTPUT_DIR", outputDir)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(runtimePath, path)
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(filepath.Join(copyPath, rel), info.ModTime(), info.ModTime())
+	})
+}
 
-		if d.sccOptions.Enabled {
-			if err := d.buildSharedClassCache(&layer, outputDir); err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to build SCC\n%w", err)
+// linkUnchangedFiles replaces the files of the runtime copy that are unchanged from those in the runtime with symlinks
+// to them. The scripts in `bin` are kept as they locate the installation from their own path.
+func linkUnchangedFiles(runtimePath string, copyPath string) error {
+	return filepath.WalkDir(runtimePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(runtimePath, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if rel == "bin" {
+				return filepath.SkipDir
 			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
 		}
 
-		libertyClasses, err := count.Classes(layer.Path)
-		if err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to count liberty classes\n%w", err)
+		target := filepath.Join(copyPath, rel)
+		if same, err := sameFile(path, target); err != nil || !same {
+			return err
+		}
+		if err := os.Remove(target); err != nil {
+			return err
 		}
+		return os.Symlink(path, target)
+	})
+}
 
-		layer.LaunchEnvironment.Default("BPL_JVM_CLASS_ADJUSTMENT", strconv.Itoa(libertyClasses))
+// sameFile returns true if the second file exists, is a regular file and has the same size and modification time as
+// the first. The copy keeps the modification times of the runtime, so any file written since has a later time. This
+// avoids reading every file of the runtime.
+func sameFile(path string, other string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	otherInfo, err := os.Lstat(other)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return otherInfo.Mode().IsRegular() && info.Size() == otherInfo.Size() && info.ModTime().Equal(otherInfo.ModTime()), nil
+}
 
-		// Used by exec.d helper
-		layer.LaunchEnvironment.Default("BPI_LIBERTY_RUNTIME_ROOT", layer.Path)
+// configureRuntime installs the features and iFixes into the runtime in the layer, builds the shared class cache and
+// sets up the launch environment and SBOM.
+func (d Distribution) configureRuntime(layer libcnb.Layer) (libcnb.Layer, error) {
+	if !d.DisableFeatureInstall {
+		for _, serverName := range d.ServerNames {
+			if err := server.InstallFeatures(layer.Path, serverName, d.FeatureCachePath, d.Executor, d.Logger); err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to install features to distribution\n%w", err)
+			}
+		}
+	} else {
+		d.Logger.Debug("Skipping feature installation")
+	}
 
-		// set logging to write to the console. Using `server run` instead of `server start` ensures that
-		// stdout/stderr are actually written to their respective streams instead of to `console.log`
-		layer.LaunchEnvironment.Default("WLP_LOGGING_MESSAGE_SOURCE", "")
-		layer.LaunchEnvironment.Default("WLP_LOGGING_CONSOLE_SOURCE", "message,trace,accessLog,ffdc,audit")
+	if err := server.InstallIFixes(layer.Path, d.IFixes, d.Executor, d.Logger); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to install iFixes to distribution\n%w", err)
+	}
 
-		// because of a liberty design decision, we can only force things to stdout if we are logging in
-		// JSON format
-		layer.LaunchEnvironment.Default("WLP_LOGGING_MESSAGE_FORMAT", "JSON")
-		layer.LaunchEnvironment.Default("WLP_LOGGING_CONSOLE_FORMAT", "JSON")
-		layer.LaunchEnvironment.Default("WLP_LOGGING_APPS_WRITE_JSON", "true")
-		layer.LaunchEnvironment.Default("WLP_LOGGING_JSON_ACCESS_LOG_FIELDS", "default")
+	// Create the output directory for Liberty
+	outputDir := filepath.Join(layer.Path, "output")
+	if err := createOutputDirectory(outputDir); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create output directory\n%w", err)
+	}
+	layer.LaunchEnvironment.Override("WLP_OUTPUT_DIR", outputDir)
 
-		if err := d.ContributeSBOM(layer); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to contribute SBOM\n%w", err)
+	if d.sccOptions.Enabled && d.sccOptions.CDS {
+		if err := d.buildCDSArchive(&layer); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to build CDS archive\n%w", err)
 		}
+	} else if d.sccOptions.Enabled {
+		if err := d.buildSharedClassCache(&layer, outputDir); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to build SCC\n%w", err)
+		}
+	}
 
-		return layer, nil
-	})
+	libertyClasses, err := count.Classes(layer.Path)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to count liberty classes\n%w", err)
+	}
+
+	layer.LaunchEnvironment.Default("BPL_JVM_CLASS_ADJUSTMENT", strconv.Itoa(libertyClasses))
+
+	// Used by exec.d helper
+	layer.LaunchEnvironment.Default("BPI_LIBERTY_RUNTIME_ROOT", layer.Path)
+
+	// set logging to write to the console. Using `server run` instead of `server start` ensures that
+	// stdout/stderr are actually written to their respective streams instead of to `console.log`
+	layer.LaunchEnvironment.Default("WLP_LOGGING_MESSAGE_SOURCE", "")
+	layer.LaunchEnvironment.Default("WLP_LOGGING_CONSOLE_SOURCE", "message,trace,accessLog,ffdc,audit")
+
+	// because of a liberty design decision, we can only force things to stdout if we are logging in
+	// JSON format
+	layer.LaunchEnvironment.Default("WLP_LOGGING_MESSAGE_FORMAT", "JSON")
+	layer.LaunchEnvironment.Default("WLP_LOGGING_CONSOLE_FORMAT", "JSON")
+	layer.LaunchEnvironment.Default("WLP_LOGGING_APPS_WRITE_JSON", "true")
+	layer.LaunchEnvironment.Default("WLP_LOGGING_JSON_ACCESS_LOG_FIELDS", "default")
+
+	if err := d.ContributeSBOM(layer); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to contribute SBOM\n%w", err)
+	}
+
+	return layer, nil
 }
 
 func (d Distribution) ContributeSBOM(layer libcnb.Layer) error {
@@ -161,7 +276,11 @@ func (d Distribution) ContributeSBOM(layer libcnb.Layer) error {
 	if err != nil {
 		return fmt.Errorf("unable to get SBOM artifact %s\n%w", d.Dependency.ID, err)
 	}
-	artifacts := []sbom.SyftArtifact{sbomArtifact}
+	var artifacts []sbom.SyftArtifact
+	if d.RuntimePath == "" {
+		// The Runtime layer describes the runtime itself when the runtime is installed on top of it
+		artifacts = append(artifacts, sbomArtifact)
+	}
 
 	installedIFixes, err := server.GetInstalledIFixes(layer.Path, d.Executor)
 	if err != nil {
@@ -244,10 +363,8 @@ func (d Distribution) buildSharedClassCache(layer *libcnb.Layer, outputDir strin
 	}
 
 	// Start and stop Liberty to build server and app shared class cache
-	for i := 0; i < d.sccOptions.NumIterations; i++ {
-		if err := d.startAndStopServer(layer.Path); err != nil {
-			return fmt.Errorf("unable to start and stop server during initial SCC layer creation\n%w", err)
-		}
+	if err := d.populateSharedClassCache(scc, layer.Path); err != nil {
+		return fmt.Errorf("unable to start and stop server during initial SCC layer creation\n%w", err)
 	}
 
 	if d.sccOptions.Trim {
@@ -258,10 +375,8 @@ func (d Distribution) buildSharedClassCache(layer *libcnb.Layer, outputDir strin
 		}
 
 		// Start and stop Liberty to build server and app shared class cache
-		for i := 0; i < d.sccOptions.NumIterations; i++ {
-			if err := d.startAndStopServer(layer.Path); err != nil {
-				return fmt.Errorf("unable to start and stop server during final SCC layer creation\n%w", err)
-			}
+		if err := d.populateSharedClassCache(scc, layer.Path); err != nil {
+			return fmt.Errorf("unable to start and stop server during final SCC layer creation\n%w", err)
 		}
 	}
 
@@ -277,40 +392,194 @@ func (d Distribution) buildSharedClassCache(layer *libcnb.Layer, outputDir strin
 	return nil
 }
 
-func (d Distribution) startAndStopServer(layerPath string) error {
-	env := os.Environ()
-	env = append(env,
-		`WLP_LOGGING_MESSAGE_SOURCE=""`,
-		"WLP_LOGGING_CONSOLE_SOURCE=message,trace,accessLog,ffdc,audit",
-		"WLP_LOGGING_MESSAGE_FORMAT=JSON",
-		"WLP_LOGGING_CONSOLE_FORMAT=JSON",
-		"WLP_LOGGING_APPS_WRITE_JSON=true",
-		"WLP_LOGGING_JSON_ACCESS_LOG_FIELDS=default",
-	)
+// buildCDSArchive cycles the server once on a HotSpot JVM to dump the classes it loaded to a dynamic CDS archive, which
+// the JVM maps at launch instead of loading those classes again
+func (d Distribution) buildCDSArchive(layer *libcnb.Layer) error {
+	d.Logger.Header("Building the CDS archive")
+
+	archive := filepath.Join(layer.Path, "cds", cdsArchiveName)
+	if err := os.MkdirAll(filepath.Dir(archive), 0755); err != nil {
+		return fmt.Errorf("unable to create CDS directory\n%w", err)
+	}
+
+	// The archive is written when the JVM exits, so the server is only cycled once
+	if err := d.startAndStopServer(layer.Path, fmt.Sprintf("JVM_ARGS=-XX:ArchiveClassesAtExit=%s", archive)); err != nil {
+		return fmt.Errorf("unable to start and stop server during CDS archive creation\n%w", err)
+	}
+
+	if exists, err := sherpa.FileExists(archive); err != nil {
+		return fmt.Errorf("unable to check CDS archive\n%w", err)
+	} else if !exists {
+		return fmt.Errorf("unable to find CDS archive %s; a dynamic CDS archive requires Java 13 or later", archive)
+	}
+
+	layer.LaunchEnvironment.Append("JAVA_TOOL_OPTIONS", " ", fmt.Sprintf("-XX:SharedArchiveFile=%s", archive))
+	return nil
+}
+
+// populateSharedClassCache cycles the server the configured number of times. With automatic iterations, the server is
+// cycled until the fill ratio of the cache stops growing.
+func (d Distribution) populateSharedClassCache(scc util.SharedClassCache, layerPath string) error {
+	if !d.sccOptions.AutoIterations {
+		for i := 0; i < d.sccOptions.NumIterations; i++ {
+			if err := d.startAndStopServer(layerPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	previous := 0.0
+	for i := 1; i <= maxAutoIterations; i++ {
+		if err := d.startAndStopServer(layerPath); err != nil {
+			return err
+		}
+		fillRatio, err := scc.GetFillRatio()
+		if err != nil {
+			return fmt.Errorf("unable to calculate fill ratio\n%w", err)
+		}
+		d.Logger.Bodyf("Shared class cache is %.1f%% full after %d iterations", fillRatio*100, i)
+		if i > 1 && fillRatio-previous < stableFillRatioDelta {
+			return nil
+		}
+		previous = fillRatio
+	}
+	return nil
+}
+
+// buildServerEnv is the logging configuration of servers started during the build. messages.log is read to tell when
+// the server is ready.
+var buildServerEnv = []string{
+	"WLP_LOGGING_MESSAGE_SOURCE=message",
+	"WLP_LOGGING_CONSOLE_SOURCE=message,trace,accessLog,ffdc,audit",
+	"WLP_LOGGING_MESSAGE_FORMAT=JSON",
+	"WLP_LOGGING_CONSOLE_FORMAT=JSON",
+	"WLP_LOGGING_APPS_WRITE_JSON=true",
+	"WLP_LOGGING_JSON_ACCESS_LOG_FIELDS=default",
+}
+
+func (d Distribution) startAndStopServer(layerPath string, serverEnv ...string) error {
+	env := append(os.Environ(), serverEnv...)
+	env = append(env, buildServerEnv...)
 	writer := io.Discard
 	if d.Logger.IsDebugEnabled() {
 		writer = d.Logger.DebugWriter()
 	}
+
+	serverName := d.serverName()
+	apps, err := server.GetConfiguredApplications(filepath.Join(serverUserPath(layerPath), "servers", serverName))
+	if err != nil {
+		return fmt.Errorf("unable to get configured applications\n%w", err)
+	}
+	logPath := serverLogPath(layerPath, serverName)
+	if err := os.Remove(logPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to remove previous server log\n%w", err)
+	}
+
 	if err := d.Executor.Execute(effect.Execution{
 		Command: filepath.Join(layerPath, "bin", "server"),
-		Args:    []string{"start"},
+		Args:    []string{"start", serverName},
 		Env:     env,
 		Stdout:  writer,
 	}); err != nil {
 		return fmt.Errorf("unable to start Liberty server\n%w", err)
 	}
+
+	// The server is stopped even if it fails to start the application or training fails, so that the build does not
+	// hang on it
+	err = server.WaitForServerReady(logPath, apps, serverReadyTimeout, time.Second)
+	if err != nil {
+		err = fmt.Errorf("unable to start the application\n%w", err)
+	} else {
+		err = d.trainServer(env, writer)
+	}
+
 	if err := d.Executor.Execute(effect.Execution{
 		Command: filepath.Join(layerPath, "bin", "server"),
-		Args:    []string{"stop"},
+		Args:    []string{"stop", serverName},
 		Stdout:  writer,
 	}); err != nil {
 		return fmt.Errorf("unable to stop Liberty server\n%w", err)
 	}
+	return err
+}
+
+func (d Distribution) serverName() string {
+	if len(d.ServerNames) > 0 {
+		return d.ServerNames[0]
+	}
+	return "defaultServer"
+}
+
+// serverLogPath returns the path of `messages.log` for the server when it is started during the build, which writes
+// its output to the user directory unless an output directory is set
+func serverLogPath(runtimePath string, serverName string) string {
+	outputDir := os.Getenv("WLP_OUTPUT_DIR")
+	if outputDir == "" {
+		outputDir = filepath.Join(serverUserPath(runtimePath), "servers")
+	}
+	return filepath.Join(outputDir, serverName, "logs", "messages.log")
+}
+
+// serverUserPath returns the user directory of the server when it is started during the build
+func serverUserPath(runtimePath string) string {
+	if userDir := os.Getenv("WLP_USER_DIR"); userDir != "" {
+		return userDir
+	}
+	return filepath.Join(runtimePath, "usr")
+}
+
+// trainServer sends the training workload to the started server, so that the classes serving requests are cached as
+// well as those loaded at startup
+func (d Distribution) trainServer(env []string, writer io.Writer) error {
+	if len(d.sccOptions.TrainingURLs) > 0 {
+		d.Logger.Bodyf("Requesting %d training URLs", len(d.sccOptions.TrainingURLs))
+	}
+	client := http.Client{Timeout: 30 * time.Second}
+	for _, trainingURL := range d.sccOptions.TrainingURLs {
+		if err := requestTrainingURL(client, trainingURL, trainingTimeout); err != nil {
+			return fmt.Errorf("unable to request training URL %s\n%w", trainingURL, err)
+		}
+	}
+
+	if d.sccOptions.TrainingScript != "" {
+		d.Logger.Bodyf("Running training script %s", d.sccOptions.TrainingScript)
+		if err := d.Executor.Execute(effect.Execution{
+			Command: d.sccOptions.TrainingScript,
+			Dir:     d.ApplicationPath,
+			Env:     append(env, fmt.Sprintf("LIBERTY_TRAINING_ENDPOINT=%s", defaultHTTPEndpoint)),
+			Stdout:  writer,
+			Stderr:  writer,
+		}); err != nil {
+			return fmt.Errorf("unable to run training script\n%w", err)
+		}
+	}
 	return nil
 }
 
+// requestTrainingURL requests the URL, retrying until the application has started and responds with anything other
+// than a server error or not found
+func requestTrainingURL(client http.Client, trainingURL string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := client.Get(trainingURL)
+		if err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusNotFound {
+				return nil
+			}
+			err = fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+}
+
 func (d Distribution) Name() string {
-	return d.LayerContributor.LayerName()
+	return fmt.Sprintf("%s-overlay", d.Dependency.ID)
 }
 
 func createOutputDirectory(path string) error {
@@ -17,10 +17,15 @@
 package liberty_test
 
 import (
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/paketo-buildpacks/liberty/internal/util"
 
@@ -42,18 +47,48 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 		Expect   = NewWithT(t).Expect
 		executor = &mocks.Executor{}
 		ctx      libcnb.BuildContext
+
+		runtimePath string
+		outputDir   string
 	)
 
+	// serverLogs makes starting the server write the messages to messages.log
+	serverLogs := func(executor *mocks.Executor, messages ...string) {
+		executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
+			return filepath.Base(e.Command) == "server" && len(e.Args) > 0 && e.Args[0] == "start"
+		})).Run(func(args mock.Arguments) {
+			logs := filepath.Join(outputDir, "defaultServer", "logs")
+			Expect(os.MkdirAll(logs, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(logs, "messages.log"), []byte(strings.Join(messages, "\n")), 0644)).To(Succeed())
+		}).Return(nil)
+	}
+	serverReady := `{"type":"liberty_message","ibm_messageId":"CWWKF0011I","message":"CWWKF0011I: The defaultServer server is ready to run a smarter planet."}`
+
 	it.Before(func() {
 		var err error
 
 		ctx.Layers.Path, err = os.MkdirTemp("", "home-layers")
 		Expect(err).NotTo(HaveOccurred())
 
+		outputDir = filepath.Join(ctx.Layers.Path, "output")
+		Expect(os.Setenv("WLP_OUTPUT_DIR", outputDir)).To(Succeed())
+
+		runtimePath = filepath.Join(ctx.Layers.Path, "open-liberty-runtime")
+		Expect(os.MkdirAll(filepath.Join(runtimePath, "bin"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(runtimePath, "lib", "features"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "bin", "server"), []byte("server"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "lib", "ws-launch.jar"), []byte("launch"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runtimePath, "lib", "features", "kernel.mf"), []byte("kernel"), 0644)).To(Succeed())
+		// The runtime is extracted from an archive, so its files are older than those written while building
+		extracted := time.Now().Add(-24 * time.Hour)
+		Expect(os.Chtimes(filepath.Join(runtimePath, "lib", "ws-launch.jar"), extracted, extracted)).To(Succeed())
+		Expect(os.Chtimes(filepath.Join(runtimePath, "lib", "features", "kernel.mf"), extracted, extracted)).To(Succeed())
+
 		executor.On("Execute", mock.Anything).Return(nil)
 	})
 
 	it.After(func() {
+		Expect(os.Unsetenv("WLP_OUTPUT_DIR")).To(Succeed())
 		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
 	})
 
@@ -63,16 +98,15 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 			URI:    "https://localhost/stub-liberty-runtime.zip",
 			SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
 		}
-		dc := libpak.DependencyCache{CachePath: "testdata"}
 
 		layer, err := ctx.Layers.Layer("test-layer")
 		Expect(err).NotTo(HaveOccurred())
 
-		distro := liberty.NewDistribution(dep, dc, "ol", "defaultServer", ctx.Application.Path, false, []string{}, []string{}, util.SharedClassCacheOptions{}, executor)
+		distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, false, []string{}, []string{}, util.SharedClassCacheOptions{}, executor)
 		distro.Logger = bard.NewLogger(io.Discard)
 
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("dependency", dep))
-		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("server-name", "defaultServer"))
+		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("server-names", []string{"defaultServer"}))
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("features", []string{}))
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("ifixes", []string{}))
 
@@ -81,7 +115,40 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 
 		Expect(layer.Launch).To(BeTrue())
 		Expect(filepath.Join(layer.Path, "bin", "server")).To(BeARegularFile())
+		Expect(os.Readlink(filepath.Join(layer.Path, "lib", "ws-launch.jar"))).To(Equal(filepath.Join(runtimePath, "lib", "ws-launch.jar")))
 		Expect(layer.LaunchEnvironment["BPI_LIBERTY_RUNTIME_ROOT.default"]).To(Equal(layer.Path))
+		Expect(distro.Name()).To(Equal("open-liberty-runtime-overlay"))
+	})
+
+	it("keeps the files changed by installing features, even if their size is unchanged", func() {
+		dep := libpak.BuildpackDependency{
+			ID:     "open-liberty-runtime",
+			URI:    "https://localhost/stub-liberty-runtime.zip",
+			SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
+		}
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		executor := &mocks.Executor{}
+		executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
+			return filepath.Base(e.Command) == "featureUtility"
+		})).Run(func(args mock.Arguments) {
+			Expect(os.WriteFile(filepath.Join(layer.Path, "lib", "features", "kernel.mf"), []byte("KERNEL"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(layer.Path, "lib", "features", "servlet-6.0.mf"), []byte("servlet"), 0644)).To(Succeed())
+		}).Return(nil)
+		executor.On("Execute", mock.Anything).Return(nil)
+
+		distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, false, []string{"servlet-6.0"}, []string{}, util.SharedClassCacheOptions{}, executor)
+		distro.Logger = bard.NewLogger(io.Discard)
+
+		layer, err = distro.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.ReadFile(filepath.Join(layer.Path, "lib", "features", "kernel.mf"))).To(Equal([]byte("KERNEL")))
+		Expect(os.ReadFile(filepath.Join(runtimePath, "lib", "features", "kernel.mf"))).To(Equal([]byte("kernel")))
+		Expect(filepath.Join(layer.Path, "lib", "features", "servlet-6.0.mf")).To(BeARegularFile())
+		Expect(os.Readlink(filepath.Join(layer.Path, "lib", "ws-launch.jar"))).To(Equal(filepath.Join(runtimePath, "lib", "ws-launch.jar")))
 	})
 
 	it("installs iFixes", func() {
@@ -90,7 +157,6 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 			URI:    "https://localhost/stub-liberty-runtime.zip",
 			SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
 		}
-		dc := libpak.DependencyCache{CachePath: "testdata"}
 
 		iFixesPath, err := os.MkdirTemp("", "ifixes")
 		Expect(err).NotTo(HaveOccurred())
@@ -102,13 +168,13 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 		layer, err := ctx.Layers.Layer("test-layer")
 		Expect(err).NotTo(HaveOccurred())
 
-		distro := liberty.NewDistribution(dep, dc, "ol", "defaultServer", ctx.Application.Path, false, []string{}, []string{iFixPath}, util.SharedClassCacheOptions{}, executor)
+		distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, false, []string{}, []string{iFixPath}, util.SharedClassCacheOptions{}, executor)
 		distro.Logger = bard.NewLogger(io.Discard)
 
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("dependency", dep))
-		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("server-name", "defaultServer"))
+		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("server-names", []string{"defaultServer"}))
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("features", []string{}))
-		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("ifixes", []string{iFixPath}))
+		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("ifixes", []string{"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}))
 
 		layer, err = distro.Contribute(layer)
 		Expect(err).NotTo(HaveOccurred())
@@ -128,7 +194,6 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 			URI:    "https://localhost/stub-liberty-runtime.zip",
 			SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
 		}
-		dc := libpak.DependencyCache{CachePath: "testdata"}
 
 		layer, err := ctx.Layers.Layer("test-layer")
 		Expect(err).NotTo(HaveOccurred())
@@ -137,11 +202,11 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 		executor.On("Execute", mock.Anything).Return(nil)
 
 		features := []string{"foo", "bar", "baz"}
-		distro := liberty.NewDistribution(dep, dc, "ol", "defaultServer", ctx.Application.Path, false, features, []string{}, util.SharedClassCacheOptions{}, executor)
+		distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, false, features, []string{}, util.SharedClassCacheOptions{}, executor)
 		distro.Logger = bard.NewLogger(io.Discard)
 
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("dependency", dep))
-		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("server-name", "defaultServer"))
+		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("server-names", []string{"defaultServer"}))
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("features", features))
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("ifixes", []string{}))
 
@@ -153,13 +218,35 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 		Expect(installFeatureExecution.Args).To(Equal([]string{"installServerFeatures", "--acceptLicense", "--noCache", "defaultServer"}))
 	})
 
+	it("installs features for each server", func() {
+		dep := libpak.BuildpackDependency{
+			ID:     "open-liberty-runtime",
+			URI:    "https://localhost/stub-liberty-runtime.zip",
+			SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
+		}
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		executor := &mocks.Executor{}
+		executor.On("Execute", mock.Anything).Return(nil)
+
+		distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer", "stubServer"}, ctx.Application.Path, false, []string{}, []string{}, util.SharedClassCacheOptions{}, executor)
+		distro.Logger = bard.NewLogger(io.Discard)
+
+		layer, err = distro.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(executor.Calls[0].Arguments[0].(effect.Execution).Args).To(Equal([]string{"installServerFeatures", "--acceptLicense", "--noCache", "defaultServer"}))
+		Expect(executor.Calls[1].Arguments[0].(effect.Execution).Args).To(Equal([]string{"installServerFeatures", "--acceptLicense", "--noCache", "stubServer"}))
+	})
+
 	it("skips installing features", func() {
 		dep := libpak.BuildpackDependency{
 			ID:     "open-liberty-runtime",
 			URI:    "https://localhost/stub-liberty-runtime.zip",
 			SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
 		}
-		dc := libpak.DependencyCache{CachePath: "testdata"}
 
 		layer, err := ctx.Layers.Layer("test-layer")
 		Expect(err).NotTo(HaveOccurred())
@@ -168,7 +255,7 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 		executor.On("Execute", mock.Anything).Return(nil)
 
 		features := []string{"foo", "bar", "baz"}
-		distro := liberty.NewDistribution(dep, dc, "ol", "defaultServer", ctx.Application.Path, true, features, []string{}, util.SharedClassCacheOptions{}, executor)
+		distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, true, features, []string{}, util.SharedClassCacheOptions{}, executor)
 		distro.Logger = bard.NewLogger(io.Discard)
 
 		Expect(distro.LayerContributor.ExpectedMetadata.(map[string]interface{})).To(HaveKeyWithValue("features", features))
@@ -179,4 +266,173 @@ func testDistribution(t *testing.T, when spec.G, it spec.S) {
 		installFeatureExecution := executor.Calls[0].Arguments[0].(effect.Execution)
 		Expect(installFeatureExecution.Command).ToNot(ContainSubstring("featureUtility"))
 	})
+
+	it("trains the server until the shared class cache stops growing", func() {
+		dep := libpak.BuildpackDependency{
+			ID:     "open-liberty-runtime",
+			URI:    "https://localhost/stub-liberty-runtime.zip",
+			SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
+		}
+
+		requests := 0
+		app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+		}))
+		defer app.Close()
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		fillRatios := []int{40, 60, 60}
+		executor := &mocks.Executor{}
+		executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
+			return len(e.Args) > 0 && strings.HasSuffix(e.Args[0], "printTopLayerStats")
+		})).Run(func(args mock.Arguments) {
+			_, err := fmt.Fprintf(args.Get(0).(effect.Execution).Stdout, "Cache is %d%% full\n", fillRatios[0])
+			Expect(err).NotTo(HaveOccurred())
+			if len(fillRatios) > 1 {
+				fillRatios = fillRatios[1:]
+			}
+		}).Return(nil)
+		serverLogs(executor, serverReady)
+		executor.On("Execute", mock.Anything).Return(nil)
+
+		sccOptions := util.SharedClassCacheOptions{
+			Enabled:        true,
+			SizeMB:         100,
+			AutoIterations: true,
+			TrainingURLs:   []string{app.URL + "/hello"},
+			TrainingScript: "/workspace/train.sh",
+		}
+		distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, true, []string{}, []string{}, sccOptions, executor)
+		distro.Logger = bard.NewLogger(io.Discard)
+
+		_, err = distro.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		var starts, scripts int
+		for _, call := range executor.Calls {
+			execution := call.Arguments[0].(effect.Execution)
+			if filepath.Base(execution.Command) == "server" && execution.Args[0] == "start" {
+				starts++
+			}
+			if execution.Command == "/workspace/train.sh" {
+				scripts++
+			}
+		}
+		Expect(starts).To(Equal(3))
+		Expect(scripts).To(Equal(3))
+		Expect(requests).To(Equal(3))
+	})
+
+	when("building a CDS archive", func() {
+		var dep libpak.BuildpackDependency
+
+		it.Before(func() {
+			dep = libpak.BuildpackDependency{
+				ID:     "open-liberty-runtime",
+				URI:    "https://localhost/stub-liberty-runtime.zip",
+				SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
+			}
+		})
+
+		it("archives the classes loaded by the server and uses the archive at launch", func() {
+			layer, err := ctx.Layers.Layer("test-layer")
+			Expect(err).NotTo(HaveOccurred())
+			archive := filepath.Join(layer.Path, "cds", "liberty.jsa")
+
+			executor := &mocks.Executor{}
+			executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
+				return filepath.Base(e.Command) == "server" && e.Args[0] == "stop"
+			})).Run(func(args mock.Arguments) {
+				Expect(os.WriteFile(archive, []byte{}, 0644)).To(Succeed())
+			}).Return(nil)
+			serverLogs(executor, serverReady)
+			executor.On("Execute", mock.Anything).Return(nil)
+
+			distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, true, []string{}, []string{}, util.SharedClassCacheOptions{Enabled: true, CDS: true}, executor)
+			distro.Logger = bard.NewLogger(io.Discard)
+
+			layer, err = distro.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			start := executor.Calls[0].Arguments[0].(effect.Execution)
+			Expect(start.Args).To(Equal([]string{"start", "defaultServer"}))
+			Expect(start.Env).To(ContainElement("JVM_ARGS=-XX:ArchiveClassesAtExit=" + archive))
+
+			Expect(filepath.Join(layer.Path, "etc", "server.env")).NotTo(BeAnExistingFile())
+			Expect(layer.LaunchEnvironment["JAVA_TOOL_OPTIONS.delim"]).To(Equal(" "))
+			Expect(layer.LaunchEnvironment["JAVA_TOOL_OPTIONS.append"]).To(Equal("-XX:SharedArchiveFile=" + archive))
+		})
+
+		it("waits for the configured applications and ignores errors that do not stop them", func() {
+			layer, err := ctx.Layers.Layer("test-layer")
+			Expect(err).NotTo(HaveOccurred())
+			archive := filepath.Join(layer.Path, "cds", "liberty.jsa")
+			serverPath := filepath.Join(runtimePath, "usr", "servers", "defaultServer")
+			Expect(os.MkdirAll(serverPath, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte(`<server>
+  <application id="app" location="app.war"/>
+</server>`), 0644)).To(Succeed())
+
+			executor := &mocks.Executor{}
+			executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
+				return filepath.Base(e.Command) == "server" && e.Args[0] == "stop"
+			})).Run(func(args mock.Arguments) {
+				Expect(os.WriteFile(archive, []byte{}, 0644)).To(Succeed())
+			}).Return(nil)
+			serverLogs(executor,
+				`{"type":"liberty_message","message":"DSRA8040E: Failed to connect to the DataSource jdbc/orders."}`,
+				`{"type":"liberty_message","message":"CWWKZ0001I: Application app started in 1.234 seconds."}`,
+				serverReady)
+			executor.On("Execute", mock.Anything).Return(nil)
+
+			distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, true, []string{}, []string{}, util.SharedClassCacheOptions{Enabled: true, CDS: true}, executor)
+			distro.Logger = bard.NewLogger(io.Discard)
+
+			_, err = distro.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("fails if the JVM did not create the archive", func() {
+			layer, err := ctx.Layers.Layer("test-layer")
+			Expect(err).NotTo(HaveOccurred())
+
+			executor := &mocks.Executor{}
+			serverLogs(executor, serverReady)
+			executor.On("Execute", mock.Anything).Return(nil)
+
+			distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, true, []string{}, []string{}, util.SharedClassCacheOptions{Enabled: true, CDS: true}, executor)
+			distro.Logger = bard.NewLogger(io.Discard)
+
+			_, err = distro.Contribute(layer)
+			Expect(err).To(MatchError(ContainSubstring("requires Java 13 or later")))
+		})
+	})
+
+	it("fails if the application does not start while building the class cache", func() {
+		dep := libpak.BuildpackDependency{
+			ID:     "open-liberty-runtime",
+			URI:    "https://localhost/stub-liberty-runtime.zip",
+			SHA256: "e71b55142699b277357d486eeb6244c71a0be3657a96a4286e30b27ceff34b17",
+		}
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		executor := &mocks.Executor{}
+		serverLogs(executor,
+			`{"type":"liberty_message","message":"CWWKZ0002E: An exception occurred while starting the application app. The exception message was: java.lang.IllegalStateException"}`,
+			serverReady)
+		executor.On("Execute", mock.Anything).Return(nil)
+
+		distro := liberty.NewDistribution(dep, runtimePath, "ol", []string{"defaultServer"}, ctx.Application.Path, true, []string{}, []string{}, util.SharedClassCacheOptions{Enabled: true, CDS: true}, executor)
+		distro.Logger = bard.NewLogger(io.Discard)
+
+		_, err = distro.Contribute(layer)
+		Expect(err).To(MatchError(ContainSubstring("CWWKZ0002E: An exception occurred while starting the application app")))
+
+		stop := executor.Calls[len(executor.Calls)-1].Arguments[0].(effect.Execution)
+		Expect(stop.Args).To(Equal([]string{"stop", "defaultServer"}))
+	})
 }
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// FeatureCache contributes the local repository that featureUtility downloads features to. The layer is only cached,
+// so features are not downloaded again on rebuilds of the same runtime version.
+type FeatureCache struct {
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+}
+
+func NewFeatureCache(version string) FeatureCache {
+	contributor := libpak.NewLayerContributor(
+		"Liberty Feature Cache",
+		map[string]interface{}{"version": version},
+		libcnb.LayerTypes{
+			Cache: true,
+		})
+
+	return FeatureCache{LayerContributor: contributor}
+}
+
+func (f FeatureCache) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	f.LayerContributor.Logger = f.Logger
+
+	return f.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+		if err := os.MkdirAll(layer.Path, 0755); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to create feature cache\n%w", err)
+		}
+		return layer, nil
+	})
+}
+
+func (FeatureCache) Name() string {
+	return "feature-cache"
+}
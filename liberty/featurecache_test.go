@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty_test
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/liberty/liberty"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testFeatureCache(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+		ctx    libcnb.BuildContext
+	)
+
+	it.Before(func() {
+		var err error
+		ctx.Layers.Path, err = os.MkdirTemp("", "feature-cache-layers")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+	})
+
+	it("contributes a cache only layer for the runtime version", func() {
+		featureCache := liberty.NewFeatureCache("24.0.0.6")
+		featureCache.Logger = bard.NewLogger(io.Discard)
+
+		Expect(featureCache.LayerContributor.ExpectedMetadata).To(Equal(map[string]interface{}{"version": "24.0.0.6"}))
+
+		layer, err := ctx.Layers.Layer(featureCache.Name())
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = featureCache.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Cache).To(BeTrue())
+		Expect(layer.Launch).To(BeFalse())
+		Expect(layer.Build).To(BeFalse())
+		Expect(layer.Path).To(BeADirectory())
+	})
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// IFix is an iFix listed in `ifixes.toml`, which is downloaded and verified during the build
+type IFix struct {
+	URI    string `toml:"uri"`
+	SHA256 string `toml:"sha256"`
+}
+
+// IFixDescriptor lists the iFixes to download, from an `ifixes.toml` in the application or the liberty binding
+type IFixDescriptor struct {
+	IFixes []IFix
+	Logger bard.Logger
+}
+
+// ReadIFixDescriptor reads the iFixes listed in the `ifixes.toml` at the given path. Returns an empty descriptor if the
+// file does not exist.
+func ReadIFixDescriptor(descriptorPath string, logger bard.Logger) (*IFixDescriptor, error) {
+	if _, err := os.Stat(descriptorPath); err != nil {
+		logger.Debugf("No iFixes descriptor found. Skipping.")
+		return &IFixDescriptor{Logger: logger}, nil
+	}
+
+	var iFixDescriptor struct {
+		IFixes []IFix `toml:"ifixes"`
+	}
+
+	if _, err := toml.DecodeFile(descriptorPath, &iFixDescriptor); err != nil {
+		return &IFixDescriptor{}, fmt.Errorf("unable to decode ifixes.toml\n%w", err)
+	}
+
+	for _, iFix := range iFixDescriptor.IFixes {
+		if iFix.URI == "" || iFix.SHA256 == "" {
+			return &IFixDescriptor{}, fmt.Errorf("iFixes in ifixes.toml require a uri and a sha256")
+		}
+	}
+
+	return &IFixDescriptor{
+		IFixes: iFixDescriptor.IFixes,
+		Logger: logger,
+	}, nil
+}
+
+// Download fetches the iFixes through the dependency cache, which verifies their checksums, and returns their paths.
+func (d *IFixDescriptor) Download(cache libpak.DependencyCache) ([]string, error) {
+	var paths []string
+	for _, iFix := range d.IFixes {
+		artifact, err := cache.Artifact(libpak.BuildpackDependency{
+			ID:     "liberty-ifix",
+			Name:   path.Base(iFix.URI),
+			URI:    iFix.URI,
+			SHA256: iFix.SHA256,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to download iFix %s\n%w", iFix.URI, err)
+		}
+		if err := artifact.Close(); err != nil {
+			return nil, fmt.Errorf("unable to close iFix %s\n%w", iFix.URI, err)
+		}
+		paths = append(paths, artifact.Name())
+	}
+	return paths, nil
+}
+
+// iFixDigests returns the SHA256 digests of the iFixes, so that layers do not depend on where the iFixes are found
+func iFixDigests(iFixes []string) []string {
+	digests := []string{}
+	for _, iFix := range iFixes {
+		digest, err := fileDigest(iFix)
+		if err != nil {
+			digest = iFix
+		}
+		digests = append(digests, digest)
+	}
+	return digests
+}
+
+func fileDigest(path string) (string, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, in); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
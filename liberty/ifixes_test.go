@@ -0,0 +1,101 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/liberty/liberty"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+)
+
+func testIFixes(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		configRoot   string
+		downloadPath string
+	)
+
+	it.Before(func() {
+		var err error
+		configRoot, err = os.MkdirTemp("", "config")
+		Expect(err).NotTo(HaveOccurred())
+		downloadPath, err = os.MkdirTemp("", "downloads")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(configRoot)).To(Succeed())
+		Expect(os.RemoveAll(downloadPath)).To(Succeed())
+	})
+
+	it("does not list iFixes without a descriptor", func() {
+		desc, err := liberty.ReadIFixDescriptor(filepath.Join(configRoot, "ifixes.toml"), bard.NewLogger(io.Discard))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(desc.IFixes).To(BeEmpty())
+	})
+
+	it("requires a checksum for each iFix", func() {
+		Expect(os.WriteFile(filepath.Join(configRoot, "ifixes.toml"), []byte(`[[ifixes]]
+uri = "https://example.com/240006-wlp-archive-ifph12345.jar"`), 0644)).To(Succeed())
+
+		_, err := liberty.ReadIFixDescriptor(filepath.Join(configRoot, "ifixes.toml"), bard.NewLogger(io.Discard))
+		Expect(err).To(MatchError(ContainSubstring("require a uri and a sha256")))
+	})
+
+	it("downloads and verifies iFixes", func() {
+		iFixPath := filepath.Join(configRoot, "240006-wlp-archive-ifph12345.jar")
+		Expect(os.WriteFile(iFixPath, []byte("ifix"), 0644)).To(Succeed())
+		digest := sha256.Sum256([]byte("ifix"))
+
+		Expect(os.WriteFile(filepath.Join(configRoot, "ifixes.toml"), []byte(fmt.Sprintf(`[[ifixes]]
+uri = "file://%s"
+sha256 = "%s"`, iFixPath, hex.EncodeToString(digest[:]))), 0644)).To(Succeed())
+
+		desc, err := liberty.ReadIFixDescriptor(filepath.Join(configRoot, "ifixes.toml"), bard.NewLogger(io.Discard))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(desc.IFixes).To(HaveLen(1))
+
+		paths, err := desc.Download(libpak.DependencyCache{DownloadPath: downloadPath, Logger: bard.NewLogger(io.Discard)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(HaveLen(1))
+		Expect(filepath.Base(paths[0])).To(Equal("240006-wlp-archive-ifph12345.jar"))
+		Expect(os.ReadFile(paths[0])).To(Equal([]byte("ifix")))
+	})
+
+	it("fails if an iFix does not match its checksum", func() {
+		iFixPath := filepath.Join(configRoot, "240006-wlp-archive-ifph12345.jar")
+		Expect(os.WriteFile(iFixPath, []byte("ifix"), 0644)).To(Succeed())
+
+		desc := liberty.IFixDescriptor{
+			IFixes: []liberty.IFix{{URI: "file://" + iFixPath, SHA256: "0000000000000000000000000000000000000000000000000000000000000000"}},
+			Logger: bard.NewLogger(io.Discard),
+		}
+		_, err := desc.Download(libpak.DependencyCache{DownloadPath: downloadPath, Logger: bard.NewLogger(io.Discard)})
+		Expect(err).To(MatchError(ContainSubstring("unable to download iFix")))
+	})
+}
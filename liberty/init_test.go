@@ -28,7 +28,15 @@ func TestUnit(t *testing.T) {
 	suite("Build", testBuild)
 	suite("Detect", testDetect)
 	suite("Distribution", testDistribution)
+	suite("Runtime", testRuntime)
 	suite("Base", testBase)
 	suite("Features", testFeatures)
+	suite("FeatureCache", testFeatureCache)
+	suite("IFixes", testIFixes)
+	suite("SpringBootLibCache", testSpringBootLibCache)
+	suite("WebAppLibs", testWebAppLibs)
+	suite("BundledRuntime", testBundledRuntime)
+	suite("StackRuntime", testStackRuntime)
+	suite("Verifier", testVerifier)
 	suite.Run(t)
 }
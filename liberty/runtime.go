@@ -0,0 +1,57 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/crush"
+)
+
+// Runtime contributes the Liberty runtime as it is distributed. The layer only depends on the distribution, so it is
+// reused when the features or iFixes change. Those are installed by the Distribution layer on top of it.
+type Runtime struct {
+	LayerContributor libpak.DependencyLayerContributor
+	Logger           bard.Logger
+}
+
+func NewRuntime(dependency libpak.BuildpackDependency, cache libpak.DependencyCache) Runtime {
+	contributor, _ := libpak.NewDependencyLayer(dependency, cache, libcnb.LayerTypes{
+		Cache:  true,
+		Launch: true,
+	})
+	return Runtime{LayerContributor: contributor}
+}
+
+func (r Runtime) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	r.LayerContributor.Logger = r.Logger
+	return r.LayerContributor.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
+		r.Logger.Bodyf("Expanding to %s", layer.Path)
+		if err := crush.ExtractZip(artifact, layer.Path, 1); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to expand Liberty Runtime\n%w", err)
+		}
+		return layer, nil
+	})
+}
+
+func (r Runtime) Name() string {
+	return r.LayerContributor.LayerName()
+}
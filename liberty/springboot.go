@@ -0,0 +1,106 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// SpringBootLibCache contributes the Liberty library index cache for a thin Spring Boot application. The libraries
+// rarely change between builds, so they are kept in their own cached layer rather than with the application.
+type SpringBootLibCache struct {
+	Libs             []util.SpringBootLib
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+}
+
+func NewSpringBootLibCache(libs []util.SpringBootLib) SpringBootLibCache {
+	digests := make([]string, 0, len(libs))
+	for _, lib := range libs {
+		digests = append(digests, lib.SHA256)
+	}
+
+	contributor := libpak.NewLayerContributor(
+		"Spring Boot Library Index Cache",
+		map[string]interface{}{"libraries": digests},
+		libcnb.LayerTypes{
+			Cache:  true,
+			Launch: true,
+		})
+
+	return SpringBootLibCache{
+		Libs:             libs,
+		LayerContributor: contributor,
+	}
+}
+
+func (s SpringBootLibCache) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	s.LayerContributor.Logger = s.Logger
+
+	layer, err := s.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+		s.Logger.Bodyf("Adding %d libraries to the library index cache", len(s.Libs))
+		if err := util.CopySpringBootLibs(s.Libs, layer.Path); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to contribute library index cache\n%w", err)
+		}
+		return layer, nil
+	})
+	if err != nil {
+		return libcnb.Layer{}, err
+	}
+
+	// The thin application refers to the libraries in this layer, so they are removed from the application on every
+	// build rather than shipping them twice and pushing them again with each change to the application
+	if err := removeSpringBootLibs(s.Libs); err != nil {
+		return libcnb.Layer{}, err
+	}
+	return layer, nil
+}
+
+// removeSpringBootLibs removes the libraries from the application, along with the library directories left empty
+func removeSpringBootLibs(libs []util.SpringBootLib) error {
+	dirs := map[string]bool{}
+	for _, lib := range libs {
+		if err := os.Remove(lib.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to remove library %s from the application\n%w", lib.Entry, err)
+		}
+		dirs[filepath.Dir(lib.Path)] = true
+	}
+
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to read library directory %s\n%w", dir, err)
+		}
+		if err == nil && len(entries) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return fmt.Errorf("unable to remove library directory %s\n%w", dir, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (SpringBootLibCache) Name() string {
+	return "spring-boot-lib-cache"
+}
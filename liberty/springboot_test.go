@@ -0,0 +1,101 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/paketo-buildpacks/liberty/liberty"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+)
+
+func testSpringBootLibCache(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+		ctx    libcnb.BuildContext
+		libDir string
+	)
+
+	it.Before(func() {
+		var err error
+		ctx.Layers.Path, err = os.MkdirTemp("", "spring-boot-layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		libDir, err = os.MkdirTemp("", "spring-boot-libs")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(libDir, "spring-core.jar"), []byte("spring-core"), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+		Expect(os.RemoveAll(libDir)).To(Succeed())
+	})
+
+	it("contributes the libraries to the library index cache", func() {
+		libs := []util.SpringBootLib{
+			{
+				Entry:  "BOOT-INF/lib/spring-core.jar",
+				Path:   filepath.Join(libDir, "spring-core.jar"),
+				SHA256: "a72402f5cb6c2ff8cc7bd4e817e894cded331a7bee543a8c7804c15d26d3f87d",
+			},
+		}
+		libCache := liberty.NewSpringBootLibCache(libs)
+		libCache.Logger = bard.NewLogger(io.Discard)
+
+		layer, err := ctx.Layers.Layer(libCache.Name())
+		Expect(err).NotTo(HaveOccurred())
+		layer, err = libCache.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Cache).To(BeTrue())
+		Expect(layer.Launch).To(BeTrue())
+		Expect(layer.Metadata).To(HaveKeyWithValue("libraries", []interface{}{libs[0].SHA256}))
+		Expect(filepath.Join(layer.Path, "a7", "2402f5cb6c2ff8cc7bd4e817e894cded331a7bee543a8c7804c15d26d3f87d.jar")).To(BeARegularFile())
+	})
+
+	it("removes the libraries from the application", func() {
+		bootLibDir := filepath.Join(libDir, "BOOT-INF", "lib")
+		Expect(os.MkdirAll(bootLibDir, 0755)).To(Succeed())
+		Expect(os.Rename(filepath.Join(libDir, "spring-core.jar"), filepath.Join(bootLibDir, "spring-core.jar"))).To(Succeed())
+		libs := []util.SpringBootLib{
+			{
+				Entry:  "BOOT-INF/lib/spring-core.jar",
+				Path:   filepath.Join(bootLibDir, "spring-core.jar"),
+				SHA256: "a72402f5cb6c2ff8cc7bd4e817e894cded331a7bee543a8c7804c15d26d3f87d",
+			},
+		}
+		libCache := liberty.NewSpringBootLibCache(libs)
+		libCache.Logger = bard.NewLogger(io.Discard)
+
+		layer, err := ctx.Layers.Layer(libCache.Name())
+		Expect(err).NotTo(HaveOccurred())
+		layer, err = libCache.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(layer.Path, "a7", "2402f5cb6c2ff8cc7bd4e817e894cded331a7bee543a8c7804c15d26d3f87d.jar")).To(BeARegularFile())
+		Expect(filepath.Join(bootLibDir, "spring-core.jar")).NotTo(BeAnExistingFile())
+		Expect(bootLibDir).NotTo(BeADirectory())
+		Expect(filepath.Join(libDir, "BOOT-INF")).To(BeADirectory())
+	})
+}
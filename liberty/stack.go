@@ -0,0 +1,90 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// StackRuntime contributes the launch environment for the Liberty runtime provided in the stack run image. The launch
+// helper links the user directory into this runtime and checks that it contains the features the servers require.
+type StackRuntime struct {
+	RuntimeRoot      string
+	ProcessType      string
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+}
+
+func NewStackRuntime(runtimeRoot string, processType string) StackRuntime {
+	contributor := libpak.NewLayerContributor(
+		"Liberty Stack Runtime",
+		map[string]interface{}{
+			"runtimeRoot": runtimeRoot,
+		},
+		libcnb.LayerTypes{
+			Launch: true,
+		})
+
+	return StackRuntime{
+		RuntimeRoot:      runtimeRoot,
+		ProcessType:      processType,
+		LayerContributor: contributor,
+	}
+}
+
+// FindStackRuntime returns the stack runtime in the image, checking for Open Liberty first and then WebSphere Liberty.
+func FindStackRuntime() (StackRuntime, error) {
+	stackRuntimes := []struct {
+		Root        string
+		ProcessType string
+	}{
+		{Root: openLibertyStackRuntimeRoot, ProcessType: "open-liberty-stack"},
+		{Root: webSphereLibertyRuntimeRoot, ProcessType: "websphere-liberty-stack"},
+	}
+
+	for _, stackRuntime := range stackRuntimes {
+		exists, err := sherpa.DirExists(stackRuntime.Root)
+		if err != nil {
+			return StackRuntime{}, fmt.Errorf("unable to check stack runtime root %s exists\n%w", stackRuntime.Root, err)
+		}
+		if exists {
+			return NewStackRuntime(stackRuntime.Root, stackRuntime.ProcessType), nil
+		}
+	}
+
+	return StackRuntime{}, fmt.Errorf("unable to find server in the stack image")
+}
+
+func (s StackRuntime) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	s.LayerContributor.Logger = s.Logger
+
+	return s.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+		layer.LaunchEnvironment.Default("BPI_LIBERTY_RUNTIME_ROOT", filepath.Join(s.RuntimeRoot, "wlp"))
+		layer.LaunchEnvironment.Default("BPI_LIBERTY_STACK_RUNTIME", "true")
+		return layer, nil
+	})
+}
+
+func (StackRuntime) Name() string {
+	return "stack-runtime"
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/liberty/internal/server"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+)
+
+// Verifier starts each server of the assembled image once to check that it is able to run the applications. The layer
+// holds nothing, so the check runs on every build rather than being restored from a previous one.
+type Verifier struct {
+	RuntimePath  string
+	ServerNames  []string
+	EndpointURLs []string
+	Executor     effect.Executor
+	Logger       bard.Logger
+}
+
+func NewVerifier(runtimePath string, serverNames []string, endpointURLs []string, executor effect.Executor) Verifier {
+	return Verifier{
+		RuntimePath:  runtimePath,
+		ServerNames:  serverNames,
+		EndpointURLs: endpointURLs,
+		Executor:     executor,
+	}
+}
+
+func (v Verifier) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	v.Logger.Header(color.BlueString("Liberty Server Verification"))
+
+	var failures []string
+	for _, serverName := range v.ServerNames {
+		v.Logger.Bodyf("Starting server %s", serverName)
+		serverFailures, err := v.verifyServer(serverName)
+		if err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to verify server %s\n%w", serverName, err)
+		}
+		for _, failure := range serverFailures {
+			failures = append(failures, fmt.Sprintf("%s: %s", serverName, failure))
+		}
+	}
+
+	if len(failures) > 0 {
+		return libcnb.Layer{}, fmt.Errorf("server verification failed with %d problems:\n  - %s",
+			len(failures), strings.Join(failures, "\n  - "))
+	}
+	v.Logger.Body("Verified that the servers start their applications without errors")
+	return layer, nil
+}
+
+// verifyServer starts the server, writing its output to a temporary directory so that nothing is left in the image,
+// and returns the checks that failed
+func (v Verifier) verifyServer(serverName string) ([]string, error) {
+	outputDir, err := os.MkdirTemp("", "liberty-verify")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create server output directory\n%w", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	apps, err := server.GetConfiguredApplications(filepath.Join(serverUserPath(v.RuntimePath), "servers", serverName))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get configured applications\n%w", err)
+	}
+
+	env := append(os.Environ(), buildServerEnv...)
+	env = append(env, fmt.Sprintf("WLP_OUTPUT_DIR=%s", outputDir))
+	writer := io.Discard
+	if v.Logger.IsDebugEnabled() {
+		writer = v.Logger.DebugWriter()
+	}
+
+	if err := v.Executor.Execute(effect.Execution{
+		Command: filepath.Join(v.RuntimePath, "bin", "server"),
+		Args:    []string{"start", serverName},
+		Env:     env,
+		Stdout:  writer,
+	}); err != nil {
+		return nil, fmt.Errorf("unable to start Liberty server\n%w", err)
+	}
+
+	// The server is stopped even if the checks fail, so that the build does not hang on it
+	failures, err := v.checkServer(filepath.Join(outputDir, serverName, "logs"), apps)
+
+	if err := v.Executor.Execute(effect.Execution{
+		Command: filepath.Join(v.RuntimePath, "bin", "server"),
+		Args:    []string{"stop", serverName},
+		Env:     env,
+		Stdout:  writer,
+	}); err != nil {
+		return nil, fmt.Errorf("unable to stop Liberty server\n%w", err)
+	}
+	return failures, err
+}
+
+// checkServer waits for the server to be ready, then checks that the applications started, that no errors or FFDC
+// incidents were logged and that the endpoints answer
+func (v Verifier) checkServer(logsPath string, apps []string) ([]string, error) {
+	var failures []string
+
+	logPath := filepath.Join(logsPath, "messages.log")
+	ready := true
+	if err := server.WaitForServerReady(logPath, apps, serverReadyTimeout, time.Second); err != nil {
+		// The error lists the messages logged and the applications that did not start, so they are not listed again
+		failures = append(failures, err.Error())
+		ready = false
+	}
+
+	if ready {
+		messages, err := server.ReadMessages(logPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read server messages\n%w", err)
+		}
+		for _, message := range messages {
+			if message.IsError() {
+				failures = append(failures, fmt.Sprintf("logged %s", message.Text))
+			}
+		}
+	}
+
+	incidents, err := server.GetFFDCIncidents(logsPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, incident := range incidents {
+		content, err := os.ReadFile(incident)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read FFDC incident\n%w", err)
+		}
+		failures = append(failures, fmt.Sprintf("logged FFDC incident %s for %s", filepath.Base(incident), ffdcException(string(content))))
+	}
+
+	if ready {
+		failures = append(failures, v.checkEndpoints()...)
+	}
+	return failures, nil
+}
+
+// checkEndpoints requests the endpoint URLs, which must answer with anything other than a server error or not found.
+// Only the listed endpoints are checked, as the server may not listen on the default HTTP endpoint.
+func (v Verifier) checkEndpoints() []string {
+	var failures []string
+	client := http.Client{Timeout: 30 * time.Second}
+	for _, endpointURL := range v.EndpointURLs {
+		resp, err := client.Get(endpointURL)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("endpoint %s did not answer: %s", endpointURL, err))
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusNotFound {
+			failures = append(failures, fmt.Sprintf("endpoint %s answered with status %s", endpointURL, resp.Status))
+		}
+	}
+	return failures
+}
+
+// ffdcException returns the exception reported by an FFDC incident, which is on the line starting `Exception = `
+func ffdcException(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if exception, found := strings.CutPrefix(strings.TrimSpace(line), "Exception = "); found {
+			return exception
+		}
+	}
+	return "an unknown exception"
+}
+
+func (Verifier) Name() string {
+	return "verify"
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/liberty/liberty"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/effect"
+	"github.com/paketo-buildpacks/libpak/effect/mocks"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+func testVerifier(t *testing.T, when spec.G, it spec.S) {
+	var (
+		Expect   = NewWithT(t).Expect
+		executor *mocks.Executor
+		userDir  string
+		endpoint *httptest.Server
+		status   int
+	)
+
+	appStarted := `{"type":"liberty_message","ibm_messageId":"CWWKZ0001I","message":"CWWKZ0001I: Application app started in 1.234 seconds."}`
+	serverReady := `{"type":"liberty_message","ibm_messageId":"CWWKF0011I","message":"CWWKF0011I: The defaultServer server is ready to run a smarter planet."}`
+
+	// serverOutput makes starting the server write the messages to messages.log and the FFDC incidents to the output
+	// directory given to the server
+	serverOutput := func(messages []string, incidents map[string]string) {
+		executor.On("Execute", mock.MatchedBy(func(e effect.Execution) bool {
+			return filepath.Base(e.Command) == "server" && e.Args[0] == "start"
+		})).Run(func(args mock.Arguments) {
+			var outputDir string
+			for _, env := range args.Get(0).(effect.Execution).Env {
+				if value, found := strings.CutPrefix(env, "WLP_OUTPUT_DIR="); found {
+					outputDir = value
+				}
+			}
+			logs := filepath.Join(outputDir, "defaultServer", "logs")
+			Expect(os.MkdirAll(filepath.Join(logs, "ffdc"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(logs, "messages.log"), []byte(strings.Join(messages, "\n")), 0644)).To(Succeed())
+			for name, content := range incidents {
+				Expect(os.WriteFile(filepath.Join(logs, "ffdc", name), []byte(content), 0644)).To(Succeed())
+			}
+		}).Return(nil)
+		executor.On("Execute", mock.Anything).Return(nil)
+	}
+
+	it.Before(func() {
+		var err error
+		userDir, err = os.MkdirTemp("", "verify")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Setenv("WLP_USER_DIR", userDir)).To(Succeed())
+
+		serverPath := filepath.Join(userDir, "servers", "defaultServer")
+		Expect(os.MkdirAll(serverPath, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(serverPath, "server.xml"), []byte(`<server>
+  <application id="app" location="app.war"/>
+</server>`), 0644)).To(Succeed())
+
+		status = http.StatusOK
+		endpoint = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		executor = &mocks.Executor{}
+	})
+
+	it.After(func() {
+		endpoint.Close()
+		Expect(os.Unsetenv("WLP_USER_DIR")).To(Succeed())
+		Expect(os.RemoveAll(userDir)).To(Succeed())
+	})
+
+	it("verifies the server", func() {
+		serverOutput([]string{appStarted, serverReady}, nil)
+
+		verifier := liberty.NewVerifier("/runtime", []string{"defaultServer"}, []string{endpoint.URL + "/health"}, executor)
+		verifier.Logger = bard.NewLogger(io.Discard)
+
+		_, err := verifier.Contribute(libcnb.Layer{})
+		Expect(err).NotTo(HaveOccurred())
+
+		start := executor.Calls[0].Arguments[0].(effect.Execution)
+		Expect(start.Command).To(Equal("/runtime/bin/server"))
+		Expect(start.Args).To(Equal([]string{"start", "defaultServer"}))
+		stop := executor.Calls[1].Arguments[0].(effect.Execution)
+		Expect(stop.Args).To(Equal([]string{"stop", "defaultServer"}))
+		Expect(stop.Env).To(Equal(start.Env))
+	})
+
+	it("only checks the listed endpoints", func() {
+		status = http.StatusNotFound
+		serverOutput([]string{appStarted, serverReady}, nil)
+
+		verifier := liberty.NewVerifier("/runtime", []string{"defaultServer"}, nil, executor)
+		verifier.Logger = bard.NewLogger(io.Discard)
+
+		_, err := verifier.Contribute(libcnb.Layer{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it("fails with a summary of the errors logged while starting", func() {
+		serverOutput([]string{
+			`{"type":"liberty_message","ibm_messageId":"CWWKZ0002E","message":"CWWKZ0002E: An exception occurred while starting the application app."}`,
+			serverReady,
+		}, map[string]string{
+			"ffdc_24.01.01_00.00.00.0.log": "------Start of DE processing------ = [1/1/24 0:00:00:000 UTC]\nException = java.lang.NullPointerException\n",
+		})
+
+		verifier := liberty.NewVerifier("/runtime", []string{"defaultServer"}, []string{endpoint.URL}, executor)
+		verifier.Logger = bard.NewLogger(io.Discard)
+
+		_, err := verifier.Contribute(libcnb.Layer{})
+		Expect(err).To(MatchError(ContainSubstring("server verification failed with 2 problems")))
+		Expect(err).To(MatchError(ContainSubstring("defaultServer: server was unable to start the applications:\nCWWKZ0002E: An exception occurred while starting the application app.")))
+		Expect(err).To(MatchError(ContainSubstring("defaultServer: logged FFDC incident ffdc_24.01.01_00.00.00.0.log for java.lang.NullPointerException")))
+		Expect(executor.Calls[1].Arguments[0].(effect.Execution).Args).To(Equal([]string{"stop", "defaultServer"}))
+	})
+
+	it("fails if the server logs other errors once the applications started", func() {
+		serverOutput([]string{
+			appStarted,
+			`{"type":"liberty_message","ibm_messageId":"DSRA8040E","message":"DSRA8040E: Failed to connect to the DataSource jdbc/orders."}`,
+			serverReady,
+		}, nil)
+
+		verifier := liberty.NewVerifier("/runtime", []string{"defaultServer"}, []string{endpoint.URL}, executor)
+		verifier.Logger = bard.NewLogger(io.Discard)
+
+		_, err := verifier.Contribute(libcnb.Layer{})
+		Expect(err).To(MatchError(ContainSubstring("server verification failed with 1 problems")))
+		Expect(err).To(MatchError(ContainSubstring("defaultServer: logged DSRA8040E: Failed to connect to the DataSource jdbc/orders.")))
+	})
+
+	it("fails if an endpoint does not answer", func() {
+		status = http.StatusInternalServerError
+		serverOutput([]string{appStarted, serverReady}, nil)
+
+		verifier := liberty.NewVerifier("/runtime", []string{"defaultServer"}, []string{endpoint.URL + "/health"}, executor)
+		verifier.Logger = bard.NewLogger(io.Discard)
+
+		_, err := verifier.Contribute(libcnb.Layer{})
+		Expect(err).To(MatchError(ContainSubstring("defaultServer: endpoint " + endpoint.URL + "/health answered with status 500 Internal Server Error")))
+	})
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty
+
+import (
+	"fmt"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// WebAppLibs contributes the third-party libraries in `WEB-INF/lib` of a compiled web archive. They rarely change
+// between builds, so they are kept in their own layer that is reused when only the application code changes. The
+// expanded application links to them.
+type WebAppLibs struct {
+	ArchivePath      string
+	Libs             []util.WebAppLib
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+}
+
+func NewWebAppLibs(archivePath string, libs []util.WebAppLib) WebAppLibs {
+	digests := make(map[string]string, len(libs))
+	for _, lib := range libs {
+		digests[lib.Name()] = lib.SHA256
+	}
+
+	contributor := libpak.NewLayerContributor(
+		"Web Application Libraries",
+		map[string]interface{}{"libraries": digests},
+		libcnb.LayerTypes{
+			Cache:  true,
+			Launch: true,
+		})
+
+	return WebAppLibs{
+		ArchivePath:      archivePath,
+		Libs:             libs,
+		LayerContributor: contributor,
+	}
+}
+
+func (w WebAppLibs) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	w.LayerContributor.Logger = w.Logger
+
+	return w.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+		w.Logger.Bodyf("Adding %d libraries from WEB-INF/lib", len(w.Libs))
+		if err := util.ExtractWebAppLibs(w.ArchivePath, w.Libs, layer.Path); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to contribute web application libraries\n%w", err)
+		}
+		return layer, nil
+	})
+}
+
+func (WebAppLibs) Name() string {
+	return "web-app-libs"
+}
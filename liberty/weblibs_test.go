@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018-2022 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package liberty_test
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/liberty/internal/util"
+	"github.com/paketo-buildpacks/liberty/liberty"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+)
+
+func testWebAppLibs(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect  = NewWithT(t).Expect
+		ctx     libcnb.BuildContext
+		warPath string
+	)
+
+	it.Before(func() {
+		var err error
+		ctx.Layers.Path, err = os.MkdirTemp("", "web-app-libs-layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		warPath = filepath.Join(ctx.Layers.Path, "app.war")
+		out, err := os.Create(warPath)
+		Expect(err).NotTo(HaveOccurred())
+		writer := zip.NewWriter(out)
+		w, err := writer.Create("WEB-INF/lib/commons-lang3.jar")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write([]byte("lang"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		Expect(out.Close()).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+	})
+
+	it("contributes the libraries of the web archive", func() {
+		libs := []util.WebAppLib{
+			{Entry: "WEB-INF/lib/commons-lang3.jar", SHA256: "5cf7d7ec1cb5c221981f277051f7f82a82e155b53c76ab9b6f75fde9641f6fa0"},
+		}
+		webAppLibs := liberty.NewWebAppLibs(warPath, libs)
+		webAppLibs.Logger = bard.NewLogger(io.Discard)
+
+		layer, err := ctx.Layers.Layer(webAppLibs.Name())
+		Expect(err).NotTo(HaveOccurred())
+		layer, err = webAppLibs.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Cache).To(BeTrue())
+		Expect(layer.Launch).To(BeTrue())
+		Expect(layer.Metadata).To(HaveKeyWithValue("libraries", map[string]interface{}{"commons-lang3.jar": libs[0].SHA256}))
+		Expect(os.ReadFile(filepath.Join(layer.Path, "commons-lang3.jar"))).To(Equal([]byte("lang")))
+	})
+}